@@ -1,12 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // App timezones work without a system zone database
 
 	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/ssh"
 	"github.com/jatsandaruwan/logx/internal/ui"
 	"github.com/jatsandaruwan/logx/internal/vault"
+	"github.com/jatsandaruwan/logx/internal/viewer"
 )
 
 const version = "1.0.0"
@@ -39,6 +50,21 @@ func main() {
 	case "editor":
 		handleEditorCommand()
 
+	case "hosts":
+		handleHostsCommand()
+
+	case "tail":
+		handleTailCommand()
+
+	case "view":
+		handleViewCommand()
+
+	case "ls":
+		handleLsCommand()
+
+	case "grep":
+		handleGrepCommand()
+
 	case "tui", "menu":
 		// Explicit TUI mode
 		if err := ui.RunMainMenu(); err != nil {
@@ -47,16 +73,13 @@ func main() {
 		}
 
 	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		fmt.Println("Run 'logx' for interactive menu or 'logx help' for command list")
-		os.Exit(1)
+		usageError(fmt.Sprintf("Unknown command: %s", command), "Run 'logx' for interactive menu or 'logx help' for command list")
 	}
 }
 
 func handleUserCommand() {
 	if len(os.Args) < 3 {
-		fmt.Println("Usage: logx user <add|list|delete> [name]")
-		os.Exit(1)
+		usageError("Usage: logx user <add|list|delete> [name]")
 	}
 
 	subcommand := os.Args[2]
@@ -76,8 +99,7 @@ func handleUserCommand() {
 
 	case "delete":
 		if len(os.Args) < 4 {
-			fmt.Println("Usage: logx user delete <name>")
-			os.Exit(1)
+			usageError("Usage: logx user delete <name>")
 		}
 		name := os.Args[3]
 		if err := deleteUser(name); err != nil {
@@ -87,16 +109,13 @@ func handleUserCommand() {
 		fmt.Printf("✓ User '%s' deleted successfully!\n", name)
 
 	default:
-		fmt.Printf("Unknown user subcommand: %s\n", subcommand)
-		fmt.Println("Available: add, list, delete")
-		os.Exit(1)
+		usageError(fmt.Sprintf("Unknown user subcommand: %s", subcommand), "Available: add, list, delete")
 	}
 }
 
 func handleAppCommand() {
 	if len(os.Args) < 3 {
-		fmt.Println("Usage: logx app <add|list|update|delete> [name]")
-		os.Exit(1)
+		usageError("Usage: logx app <add|list|update|delete> [name]")
 	}
 
 	subcommand := os.Args[2]
@@ -116,8 +135,7 @@ func handleAppCommand() {
 
 	case "update":
 		if len(os.Args) < 4 {
-			fmt.Println("Usage: logx app update <name>")
-			os.Exit(1)
+			usageError("Usage: logx app update <name>")
 		}
 		name := os.Args[3]
 		if err := ui.UpdateAppInteractive(name); err != nil {
@@ -127,8 +145,7 @@ func handleAppCommand() {
 
 	case "delete":
 		if len(os.Args) < 4 {
-			fmt.Println("Usage: logx app delete <name>")
-			os.Exit(1)
+			usageError("Usage: logx app delete <name>")
 		}
 		name := os.Args[3]
 		if err := deleteApp(name); err != nil {
@@ -138,16 +155,13 @@ func handleAppCommand() {
 		fmt.Printf("✓ App '%s' deleted successfully!\n", name)
 
 	default:
-		fmt.Printf("Unknown app subcommand: %s\n", subcommand)
-		fmt.Println("Available: add, list, update, delete")
-		os.Exit(1)
+		usageError(fmt.Sprintf("Unknown app subcommand: %s", subcommand), "Available: add, list, update, delete")
 	}
 }
 
 func handleEditorCommand() {
 	if len(os.Args) < 3 {
-		fmt.Println("Usage: logx editor <set|show> [editor-command]")
-		os.Exit(1)
+		usageError("Usage: logx editor <set|show> [editor-command]")
 	}
 
 	subcommand := os.Args[2]
@@ -155,9 +169,7 @@ func handleEditorCommand() {
 	switch subcommand {
 	case "set":
 		if len(os.Args) < 4 {
-			fmt.Println("Usage: logx editor set <editor-command>")
-			fmt.Println("Example: logx editor set \"code\"")
-			os.Exit(1)
+			usageError("Usage: logx editor set <editor-command>", "Example: logx editor set \"code\"")
 		}
 		editor := os.Args[3]
 		if err := setEditor(editor); err != nil {
@@ -179,12 +191,323 @@ func handleEditorCommand() {
 		}
 
 	default:
-		fmt.Printf("Unknown editor subcommand: %s\n", subcommand)
-		fmt.Println("Available: set, show")
+		usageError(fmt.Sprintf("Unknown editor subcommand: %s", subcommand), "Available: set, show")
+	}
+}
+
+func handleHostsCommand() {
+	if len(os.Args) < 3 {
+		usageError("Usage: logx hosts <list|trust|forget> [host]")
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "list":
+		if err := ui.ListKnownHosts(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "trust":
+		const usage = "Usage: logx hosts trust <host[:port]> [--app NAME]"
+		positional, flags := parseArgs(os.Args[3:], usage, []string{"--app"}, nil)
+		if len(positional) != 1 {
+			usageError(usage)
+		}
+		host := positional[0]
+		addr, err := ui.TrustHostInteractive(host, flags["--app"])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if strings.TrimSuffix(addr, ":22") != host {
+			fmt.Printf("✓ Host '%s' trusted as %s\n", host, addr)
+		} else {
+			fmt.Printf("✓ Host '%s' trusted\n", host)
+		}
+
+	case "forget":
+		if len(os.Args) < 4 {
+			usageError("Usage: logx hosts forget <host[:port]>")
+		}
+		host := os.Args[3]
+		removed, err := ssh.ForgetHost(host)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if removed == 0 {
+			fmt.Printf("No logx known_hosts entries for '%s'\n", host)
+		} else {
+			fmt.Printf("✓ Removed %d key(s) for '%s'\n", removed, host)
+		}
+
+	default:
+		usageError(fmt.Sprintf("Unknown hosts subcommand: %s", subcommand), "Available: list, trust, forget")
+	}
+}
+
+func handleTailCommand() {
+	usage := "Usage: logx tail <app> [--server <host>] [--grep <pattern>]"
+
+	args, flags := parseArgs(os.Args[2:], usage, []string{"--server", "--grep"}, nil)
+	if len(args) != 1 {
+		usageError(usage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := viewer.TailLogs(ctx, args[0], flags["--server"], flags["--grep"]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+func handleViewCommand() {
+	usage := "Usage: logx view <app> [--date <date> | --current | --index <n>] [--server <host>] [--editor | --internal]\n" +
+		"                 [--compare <date>] [--workers <n>] [--timeout <duration>]\n" +
+		"       logx view <app> --since <time> [--until <time>] [--server <host>] [--editor | --internal]\n" +
+		"       logx view <app> --list [--server <host>]"
+
+	args, flags := parseArgs(os.Args[2:], usage,
+		[]string{"--date", "--server", "--compare", "--workers", "--timeout", "--index", "--since", "--until"},
+		[]string{"--current", "--editor", "--internal", "--list"})
+	if len(args) != 1 {
+		usageError(usage)
+	}
+	_, current := flags["--current"]
+	_, internal := flags["--internal"]
+	_, external := flags["--editor"]
+	_, list := flags["--list"]
+	_, hasIndex := flags["--index"]
+	_, hasSince := flags["--since"]
+	_, hasUntil := flags["--until"]
+	compare := flags["--compare"]
+	if current && (flags["--date"] != "" || compare != "") || internal && external || compare != "" && external ||
+		hasIndex && (current || flags["--date"] != "" || compare != "") ||
+		(hasSince || hasUntil) && (current || hasIndex || flags["--date"] != "" || compare != "") {
+		usageError(usage)
+	}
+
+	opts := viewer.ViewOptions{
+		Server:   flags["--server"],
+		OpenWith: viewer.OpenEditor,
+		Compare:  compare,
+	}
+	if internal {
+		opts.OpenWith = viewer.OpenInternal
+	}
+	if hasSince || hasUntil {
+		// Read in the app's timezone once the config is loaded
+		if flags["--since"] == "" {
+			usageError("Invalid time window: a time window needs a start (--since)")
+		}
+		opts.Since, opts.Until = flags["--since"], flags["--until"]
+	}
+	if hasIndex {
+		index, err := strconv.Atoi(flags["--index"])
+		if err != nil || index < 1 {
+			usageError(fmt.Sprintf("Invalid --index value: %s (use 1 for app.log.1)", flags["--index"]))
+		}
+		opts.Index = index
+	}
+	if value := flags["--workers"]; value != "" {
+		workers, err := strconv.Atoi(value)
+		if err != nil || workers < 1 {
+			usageError(fmt.Sprintf("Invalid --workers value: %s", value))
+		}
+		opts.Workers = workers
+	}
+	if value := flags["--timeout"]; value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			usageError(fmt.Sprintf("Invalid --timeout value: %s (use e.g. 30s, 2m)", value))
+		}
+		opts.Timeout = timeout
+	}
+
+	var err error
+	if list {
+		err = viewer.ListGenerations(args[0], opts)
+	} else if current {
+		err = viewer.ViewCurrentLogs(args[0], opts)
+	} else {
+		err = viewer.ViewLogs(args[0], flags["--date"], opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleLsCommand() {
+	usage := "Usage: logx ls <app> [--server <host>]"
+
+	args, flags := parseArgs(os.Args[2:], usage, []string{"--server"}, nil)
+	if len(args) != 1 {
+		usageError(usage)
+	}
+
+	if err := viewer.ListLogs(args[0], viewer.ViewOptions{Server: flags["--server"]}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleGrepCommand() {
+	usage := "Usage: logx grep <app> <pattern> [--date <date> | --current | --since <time> [--until <time>]] [--server <host>]\n" +
+		"       logx grep <pattern> (--apps <a,b> | --all-apps) [--date <date> | --current | --since <time> [--until <time>]]\n" +
+		"                 [--server <host>] [--merge]\n" +
+		"       options: [-i] [-F] [-A <n>] [-B <n>] [-C <n>] [-e <pattern>]\n" +
+		"       Exit status: 0 if anything matched, 1 if nothing did, 2 on errors"
+
+	args, flags := parseArgs(os.Args[2:], usage,
+		[]string{"--date", "--server", "--since", "--until", "--apps", "-A", "-B", "-C", "-e"},
+		[]string{"--current", "--all-apps", "--merge", "-i", "-F"})
+
+	// "<app> <pattern>" searches one app; a pattern alone searches several.
+	// A pattern starting with "-" is given with -e.
+	pattern, hasPattern := flags["-e"]
+	if !hasPattern {
+		if len(args) == 0 {
+			usageError(usage)
+		}
+		pattern, args = args[len(args)-1], args[:len(args)-1]
+	}
+	if len(args) > 1 {
+		usageError(usage)
+	}
+
+	_, current := flags["--current"]
+	_, allApps := flags["--all-apps"]
+	_, merge := flags["--merge"]
+	_, hasApps := flags["--apps"]
+	_, hasSince := flags["--since"]
+	_, hasUntil := flags["--until"]
+	window := hasSince || hasUntil
+	if current && flags["--date"] != "" || window && (current || flags["--date"] != "") ||
+		hasApps && allApps || len(args) == 1 && (hasApps || allApps || merge) {
+		usageError(usage)
+	}
+	if len(args) == 0 && !hasApps && !allApps {
+		usageError("Give --apps or --all-apps to search several apps", usage)
+	}
+	if window && flags["--since"] == "" {
+		usageError("Invalid time window: a time window needs a start (--since)")
+	}
+
+	opts := viewer.GrepOptions{
+		Server:  flags["--server"],
+		Date:    flags["--date"],
+		Current: current,
+		Since:   flags["--since"],
+		Until:   flags["--until"],
+		Merge:   merge,
+	}
+	opts.Search.Pattern = pattern
+	_, opts.Search.IgnoreCase = flags["-i"]
+	_, opts.Search.Fixed = flags["-F"]
+	for _, name := range []string{"-C", "-A", "-B"} {
+		value, ok := flags[name]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			usageError(fmt.Sprintf("Invalid %s value: %s", name, value))
+		}
+		switch name {
+		case "-C":
+			opts.Search.Before, opts.Search.After = n, n
+		case "-A":
+			opts.Search.After = n
+		case "-B":
+			opts.Search.Before = n
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	if len(args) == 1 {
+		err = viewer.GrepLogs(ctx, args[0], opts)
+	} else {
+		var apps []string
+		if hasApps {
+			for _, name := range strings.Split(flags["--apps"], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					apps = append(apps, name)
+				}
+			}
+			if len(apps) == 0 {
+				usageError(usage)
+			}
+		}
+		err = viewer.GrepApps(ctx, apps, opts)
+	}
+
+	// Exit like grep: 1 when nothing matched, 2 on errors, and like a shell
+	// command killed by Ctrl+C when interrupted
+	switch {
+	case errors.Is(err, viewer.ErrNoMatch):
+		os.Exit(1)
+	case errors.Is(err, context.Canceled):
+		os.Exit(130)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+// usageError prints what is wrong with the command line and exits with
+// status 2, as every command does when it is used wrongly
+func usageError(lines ...string) {
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	os.Exit(2)
+}
+
+// parseArgs splits command arguments into positional arguments and flags.
+// Flags in withValue take a value, given as "--flag value" or "--flag=value";
+// flags in boolean are present with an empty value. Anything else starting
+// with "-", or a missing value, prints usage and exits with status 2.
+func parseArgs(args []string, usage string, withValue, boolean []string) ([]string, map[string]string) {
+	var positional []string
+	flags := make(map[string]string)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+
+		switch {
+		case slices.Contains(withValue, name):
+			if !hasValue {
+				if i+1 >= len(args) {
+					usageError(fmt.Sprintf("Missing value for %s", name), usage)
+				}
+				i++
+				value = args[i]
+			}
+			flags[name] = value
+
+		case slices.Contains(boolean, arg):
+			flags[arg] = ""
+
+		case strings.HasPrefix(arg, "-"):
+			usageError(fmt.Sprintf("Unknown option: %s", arg), usage)
+
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	return positional, flags
+}
+
 func deleteUser(name string) error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -237,6 +560,22 @@ func printHelp() {
 	fmt.Println("  user <add|list|delete>         Manage users")
 	fmt.Println("  app <add|list|update|delete>   Manage applications")
 	fmt.Println("  editor <set|show>              Manage editor settings")
+	fmt.Println("  hosts <list|trust|forget>      Manage trusted SSH host keys")
+	fmt.Println("  view <app> [--date <date> | --current | --index <n>] [--server <host>] [--editor | --internal]")
+	fmt.Println("       [--compare <date>] [--workers <n>] [--timeout <duration>]")
+	fmt.Println("                                 Download an app's logs and open them")
+	fmt.Println("  view <app> --since <time> [--until <time>] [--server <host>] [--editor | --internal]")
+	fmt.Println("                                 Open the lines between two times, across rotated files")
+	fmt.Println("  view <app> --list [--server <host>]")
+	fmt.Println("                                 List numbered rotations of an app's log")
+	fmt.Println("  ls <app> [--server <host>]     List the dated logs on each server")
+	fmt.Println("  grep <app> <pattern> [--date <date> | --current | --since <time> [--until <time>]]")
+	fmt.Println("       [--server <host>] [-i] [-F] [-A|-B|-C <n>]")
+	fmt.Println("                                 Search a log on the servers, fetching only matching lines")
+	fmt.Println("  grep <pattern> (--apps <a,b> | --all-apps) [--merge] [...]")
+	fmt.Println("                                 Search every app, or some, on all their servers at once")
+	fmt.Println("  tail <app> [--server <host>] [--grep <pattern>]")
+	fmt.Println("                                 Follow an app's log on all servers")
 	fmt.Println("  version                        Show version")
 	fmt.Println("  help                           Show this help")
 	fmt.Println()
@@ -250,6 +589,14 @@ func printHelp() {
 	fmt.Println("  logx                    # Launch interactive menu")
 	fmt.Println("  logx user add           # Add user via CLI")
 	fmt.Println("  logx app list           # List apps via CLI")
+	fmt.Println("  logx view myapp --date yesterday")
+	fmt.Println("  logx ls myapp           # Which days have logs")
+	fmt.Println("  logx grep myapp req-42 --date yesterday -C 3")
+	fmt.Println("  logx grep order-1234 --all-apps --since -1d --merge")
+	fmt.Println()
+	fmt.Println("Dates (--date, --compare, --since, --until and the TUI date step):")
+	fmt.Println("  2025-10-04, today, yesterday, -2d (days ago), -1w (weeks ago),")
+	fmt.Println("  monday or mon (most recent, today included), last monday (before today)")
 	fmt.Println()
 	fmt.Println("Log Viewer Controls (in TUI):")
 	fmt.Println("  ↑/↓ or j/k    Navigate lines")
@@ -257,11 +604,16 @@ func printHelp() {
 	fmt.Println("  g/G           Go to top/bottom")
 	fmt.Println("  /             Search")
 	fmt.Println("  n/N           Next/previous match")
+	fmt.Println("  f             Follow file (tail -F)")
+	fmt.Println("  Tab/Shift+Tab Switch server (All Servers)")
+	fmt.Println("  v             Split panes side by side")
+	fmt.Println("  =             Sync split panes by line/timestamp")
 	fmt.Println("  s             Save log locally")
 	fmt.Println("  q or Ctrl+C   Quit")
 	fmt.Println()
 	fmt.Println("Configuration:")
 	fmt.Println("  Config: ~/.config/logx/config.xml")
 	fmt.Println("  Credentials: System keyring")
+	fmt.Println("  Host keys: ~/.ssh/known_hosts, ~/.config/logx/known_hosts")
 	fmt.Println()
 }
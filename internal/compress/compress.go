@@ -0,0 +1,92 @@
+// Package compress reads log files compressed by logrotate
+package compress
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Extensions are the compressed file suffixes understood, in the order
+// they are looked for on a server
+var Extensions = []string{".gz", ".bz2", ".zst", ".xz"}
+
+// Ext returns the compression suffix of a file name, or "" if the file is
+// not compressed
+func Ext(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	for _, known := range Extensions {
+		if ext == known {
+			return known
+		}
+	}
+	return ""
+}
+
+// IsCompressed reports whether a file name has a compression suffix
+func IsCompressed(name string) bool {
+	return Ext(name) != ""
+}
+
+// NewReader decompresses r, which holds a file with the suffix ext
+func NewReader(r io.Reader, ext string) (io.ReadCloser, error) {
+	switch ext {
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".bz2":
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case ".zst":
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case ".xz":
+		x, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression: %q", ext)
+	}
+}
+
+// DecompressFile writes the decompressed contents of src to dst. A partly
+// written dst is never left behind.
+func DecompressFile(src, dst, ext string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := NewReader(in, ext)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // Config represents the root configuration
@@ -13,6 +15,13 @@ type Config struct {
 	Users   Users    `xml:"users"`
 	Apps    Apps     `xml:"apps"`
 	Editor  string   `xml:"editor,omitempty"`
+	Fetch   *Fetch   `xml:"fetch,omitempty"`
+}
+
+// Fetch limits how logs are downloaded from many servers at once
+type Fetch struct {
+	Workers int    `xml:"workers,attr,omitempty"` // Servers fetched in parallel
+	Timeout string `xml:"timeout,attr,omitempty"` // Per-server limit, e.g. "45s"
 }
 
 // Users contains all user configurations
@@ -22,9 +31,11 @@ type Users struct {
 
 // User represents SSH user credentials reference
 type User struct {
-	ID       string `xml:"id,attr"`
-	Name     string `xml:"name,attr"`
-	Username string `xml:"username,attr"`
+	ID       string   `xml:"id,attr"`
+	Name     string   `xml:"name,attr"`
+	Username string   `xml:"username,attr"`
+	Auth     []string `xml:"auth>method,omitempty"`
+	KeyFiles []string `xml:"key-files>key-file,omitempty"`
 }
 
 // Apps contains all application configurations
@@ -39,11 +50,246 @@ type App struct {
 	LogPath    string   `xml:"log-path"`
 	LogPattern string   `xml:"log-pattern"`
 	DateFormat string   `xml:"date-format"`
-	Servers    []string `xml:"servers>server"`
+	Rotation   string   `xml:"rotation,omitempty"`  // RotationDate (the default) or RotationNumeric
+	Timezone   string   `xml:"timezone,omitempty"`  // IANA name, e.g. "UTC" or "Asia/Colombo"; local time if empty
+	Transport  string   `xml:"transport,omitempty"` // TransportSSH (the default), TransportLocal, TransportDocker or TransportKubectl
+	Jump       []Hop    `xml:"jump>host,omitempty"`
+	Servers    []Server `xml:"servers>server"`
+
+	// For TransportDocker and TransportKubectl, where servers name containers or pods
+	CLIHost   string `xml:"cli-host,omitempty"`  // Server to run docker or kubectl on over SSH; this machine if empty
+	Namespace string `xml:"namespace,omitempty"` // Kubernetes namespace; kubectl's current one if empty
+	Container string `xml:"container,omitempty"` // Container within each pod; kubectl's default if empty
+	Selector  string `xml:"selector,omitempty"`  // Label selector finding the pods when no servers are listed
 }
 
-// GetConfigPath returns the platform-specific config file path
-func GetConfigPath() (string, error) {
+// Server represents a host an app writes logs on
+type Server struct {
+	Host string `xml:",chardata"`
+	// Jump overrides the app's jump hosts for this server, as a comma
+	// separated chain of [user-ref@]host[:port]; "none" connects directly
+	Jump string `xml:"jump,attr,omitempty"`
+	// Timezone overrides the app's timezone for this server
+	Timezone string `xml:"timezone,attr,omitempty"`
+	// CLIHost overrides the app's cli-host for this container or pod
+	CLIHost string `xml:"cli-host,attr,omitempty"`
+}
+
+// Hop represents a jump host (bastion) on the way to a server
+type Hop struct {
+	Host    string `xml:",chardata"`
+	UserRef string `xml:"user-ref,attr,omitempty"`
+}
+
+// How an app's older logs are named
+const (
+	RotationDate    = "date"    // LogPattern with the day in place of {date}
+	RotationNumeric = "numeric" // logrotate numbering: app.log.1, app.log.2.gz, ...
+)
+
+// How an app's logs are reached
+const (
+	TransportSSH     = "ssh"     // On each server, over SSH
+	TransportLocal   = "local"   // On this machine
+	TransportDocker  = "docker"  // From docker logs, each server a container
+	TransportKubectl = "kubectl" // From kubectl logs, each server a pod
+)
+
+// LocalHost is the server a local app's logs are shown under when it lists
+// no servers
+const LocalHost = "localhost"
+
+// Local reports whether the app's logs are read from this machine
+func (a *App) Local() bool {
+	return a.Transport == TransportLocal
+}
+
+// Containers reports whether the app's logs come from docker or kubectl
+// rather than files
+func (a *App) Containers() bool {
+	return a.Transport == TransportDocker || a.Transport == TransportKubectl
+}
+
+// SelectsPods reports whether the app's hosts are the pods matching its
+// selector, found when its logs are read, rather than listed servers
+func (a *App) SelectsPods() bool {
+	return a.Transport == TransportKubectl && a.Selector != "" && len(a.Servers) == 0
+}
+
+// CLIHostFor returns the server that runs docker or kubectl for a
+// container or pod, or "" for this machine
+func (a *App) CLIHostFor(host string) string {
+	if server := a.GetServer(host); server.CLIHost != "" {
+		return server.CLIHost
+	}
+	return a.CLIHost
+}
+
+// UsesSSH reports whether reading the app's logs needs an SSH login:
+// always for SSH apps, and for docker and kubectl apps that run the CLI
+// on a cli-host
+func (a *App) UsesSSH() bool {
+	switch {
+	case a.Local():
+		return false
+	case a.Containers():
+		if a.CLIHost != "" {
+			return true
+		}
+		for _, server := range a.Servers {
+			if server.CLIHost != "" {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// CheckTransport reports a transport the app sets that logx doesn't know
+func (a *App) CheckTransport() error {
+	switch a.Transport {
+	case "", TransportSSH, TransportLocal, TransportDocker, TransportKubectl:
+		return nil
+	default:
+		return fmt.Errorf("unknown transport %q in app %s", a.Transport, a.Name)
+	}
+}
+
+// NumericRotation reports whether older logs are numbered rather than dated
+func (a *App) NumericRotation() bool {
+	return a.Rotation == RotationNumeric
+}
+
+// Location returns the timezone a server's logs are dated in: the
+// server's own, else the app's, else local time. An empty host gives the
+// app's timezone. Invalid names fall back to local time; CheckTimezones
+// reports them.
+func (a *App) Location(host string) *time.Location {
+	name := a.GetServer(host).Timezone
+	if name == "" {
+		name = a.Timezone
+	}
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// CheckTimezones reports the first timezone of the app or its servers that
+// is not a valid IANA name
+func (a *App) CheckTimezones() error {
+	names := []string{a.Timezone}
+	for _, server := range a.Servers {
+		names = append(names, server.Timezone)
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, err := time.LoadLocation(name); err != nil {
+			return fmt.Errorf("invalid timezone %q in app %s: %w", name, a.Name, err)
+		}
+	}
+	return nil
+}
+
+// NoJump disables the app's jump hosts for a single server
+const NoJump = "none"
+
+// Hosts returns the host names of all servers. A local app without
+// servers has the single LocalHost.
+func (a *App) Hosts() []string {
+	if a.Local() && len(a.Servers) == 0 {
+		return []string{LocalHost}
+	}
+	hosts := make([]string, 0, len(a.Servers))
+	for _, server := range a.Servers {
+		hosts = append(hosts, server.Host)
+	}
+	return hosts
+}
+
+// GetServer finds a server by host, falling back to a bare server entry
+// for hosts that are not listed in the app
+func (a *App) GetServer(host string) Server {
+	for _, server := range a.Servers {
+		if server.Host == host {
+			return server
+		}
+	}
+	return Server{Host: host}
+}
+
+// JumpFor returns the jump host chain used to reach a server, in the order
+// the hops are connected
+func (a *App) JumpFor(server Server) []Hop {
+	switch strings.TrimSpace(server.Jump) {
+	case "":
+		return a.Jump
+	case NoJump:
+		return nil
+	default:
+		return ParseHops(server.Jump)
+	}
+}
+
+// ParseHops parses a comma separated jump chain of [user-ref@]host[:port]
+func ParseHops(spec string) []Hop {
+	var hops []Hop
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		hop := Hop{Host: part}
+		if i := strings.LastIndex(part, "@"); i >= 0 {
+			hop.UserRef = part[:i]
+			hop.Host = part[i+1:]
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// FormatHops is the inverse of ParseHops
+func FormatHops(hops []Hop) string {
+	parts := make([]string, 0, len(hops))
+	for _, hop := range hops {
+		if hop.UserRef != "" {
+			parts = append(parts, hop.UserRef+"@"+hop.Host)
+		} else {
+			parts = append(parts, hop.Host)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// FetchLimits returns the configured number of parallel fetches and the
+// per-server timeout. Zero values mean the defaults should be used.
+func (c *Config) FetchLimits() (int, time.Duration, error) {
+	if c.Fetch == nil {
+		return 0, 0, nil
+	}
+
+	var timeout time.Duration
+	if c.Fetch.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(c.Fetch.Timeout)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid fetch timeout %q: %w", c.Fetch.Timeout, err)
+		}
+	}
+	return c.Fetch.Workers, timeout, nil
+}
+
+// GetConfigDir returns the platform-specific logx config directory
+func GetConfigDir() (string, error) {
 	var configDir string
 
 	if os.Getenv("XDG_CONFIG_HOME") != "" {
@@ -61,6 +307,31 @@ func GetConfigPath() (string, error) {
 		return "", err
 	}
 
+	return logxDir, nil
+}
+
+// GetCacheDir returns the platform-specific logx cache directory
+func GetCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	logxDir := filepath.Join(cacheDir, "logx")
+	if err := os.MkdirAll(logxDir, 0700); err != nil {
+		return "", err
+	}
+
+	return logxDir, nil
+}
+
+// GetConfigPath returns the platform-specific config file path
+func GetConfigPath() (string, error) {
+	logxDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
 	return filepath.Join(logxDir, "config.xml"), nil
 }
 
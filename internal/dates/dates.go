@@ -0,0 +1,63 @@
+// Package dates reads the dates people type: ISO dates and relative
+// expressions such as "yesterday", "-2d" or "last monday"
+package dates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// Parse resolves expr to the start of a day in now's location. It accepts
+// YYYY-MM-DD, "today", "yesterday", "-Nd" and "-Nw" (days and weeks ago),
+// weekday names ("monday", "mon"), meaning the most recent such day with
+// today included, and "last <weekday>", which never means today.
+func Parse(expr string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	expr = strings.ToLower(strings.Join(strings.Fields(expr), " "))
+
+	if t, err := time.ParseInLocation("2006-01-02", expr, now.Location()); err == nil {
+		return t, nil
+	}
+
+	switch expr {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if rest, ok := strings.CutPrefix(expr, "-"); ok && len(rest) > 1 {
+		n, err := strconv.Atoi(rest[:len(rest)-1])
+		if err == nil && n >= 0 {
+			switch rest[len(rest)-1] {
+			case 'd':
+				return today.AddDate(0, 0, -n), nil
+			case 'w':
+				return today.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+
+	name, last := strings.CutPrefix(expr, "last ")
+	if day, ok := weekdays[name]; ok {
+		back := (int(today.Weekday()) - int(day) + 7) % 7
+		if back == 0 && last {
+			back = 7
+		}
+		return today.AddDate(0, 0, -back), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q. Use YYYY-MM-DD, today, yesterday, -2d, -1w or a weekday", expr)
+}
@@ -0,0 +1,90 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("UTC-3", -3*60*60)
+	// A Wednesday afternoon
+	now := time.Date(2025, 10, 8, 15, 30, 0, 0, loc)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{expr: "2025-10-04", want: day(10, 4)},
+		{expr: "2024-02-29", want: time.Date(2024, 2, 29, 0, 0, 0, 0, loc)},
+		{expr: "today", want: day(10, 8)},
+		{expr: "Today", want: day(10, 8)},
+		{expr: " yesterday ", want: day(10, 7)},
+		{expr: "-0d", want: day(10, 8)},
+		{expr: "-2d", want: day(10, 6)},
+		{expr: "-10d", want: day(9, 28)},
+		{expr: "-1w", want: day(10, 1)},
+		{expr: "-2w", want: day(9, 24)},
+
+		// Weekday names mean the most recent such day, today included
+		{expr: "wednesday", want: day(10, 8)},
+		{expr: "wed", want: day(10, 8)},
+		{expr: "tuesday", want: day(10, 7)},
+		{expr: "monday", want: day(10, 6)},
+		{expr: "Mon", want: day(10, 6)},
+		{expr: "thursday", want: day(10, 2)},
+		{expr: "thurs", want: day(10, 2)},
+		{expr: "sunday", want: day(10, 5)},
+
+		// "last" never means today
+		{expr: "last wednesday", want: day(10, 1)},
+		{expr: "last  Wed", want: day(10, 1)},
+		{expr: "last tuesday", want: day(10, 7)},
+		{expr: "last thursday", want: day(10, 2)},
+
+		{expr: "", wantErr: true},
+		{expr: "tomorrow", wantErr: true},
+		{expr: "-d", wantErr: true},
+		{expr: "-2", wantErr: true},
+		{expr: "-2m", wantErr: true},
+		{expr: "--2d", wantErr: true},
+		{expr: "2d", wantErr: true},
+		{expr: "last", wantErr: true},
+		{expr: "last today", wantErr: true},
+		{expr: "2025-13-01", wantErr: true},
+		{expr: "2025-02-30", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expr, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want an error", tt.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != loc {
+			t.Errorf("Parse(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseNearMidnight(t *testing.T) {
+	// The day is the one in now's location, not in UTC
+	loc := time.FixedZone("UTC+9", 9*60*60)
+	now := time.Date(2025, 10, 8, 23, 59, 59, 0, time.UTC).In(loc) // Thursday morning there
+
+	got, err := Parse("yesterday", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 10, 8, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Parse(yesterday) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Package logtime finds and parses the timestamps that log lines start with
+package logtime
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// searchWidth is how far into a line a timestamp is looked for, which
+// allows for a level or bracket in front of it
+const searchWidth = 64
+
+type format struct {
+	re      *regexp.Regexp
+	layouts []string
+	noYear  bool // Layout has no year, as in syslog
+}
+
+// formats are the timestamp styles recognised, most common first
+var formats = []format{
+	{
+		// 2025-10-04 12:34:56, 2025-10-04T12:34:56.123Z, 2025-10-04 12:34:56,123 +0200
+		re: regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d{1,9})?(?: ?(?:Z|[+-]\d{2}:?\d{2}))?`),
+		layouts: []string{
+			"2006-01-02 15:04:05.999999999Z07:00",
+			"2006-01-02 15:04:05.999999999Z0700",
+			"2006-01-02 15:04:05.999999999 -07:00",
+			"2006-01-02 15:04:05.999999999 -0700",
+			"2006-01-02 15:04:05.999999999",
+		},
+	},
+	{
+		// 2025/10/04 12:34:56 (Go log package, nginx error log)
+		re:      regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:[.,]\d{1,9})?`),
+		layouts: []string{"2006/01/02 15:04:05.999999999"},
+	},
+	{
+		// 04/Oct/2025:12:34:56 +0000 (Apache and nginx access logs)
+		re:      regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`),
+		layouts: []string{"02/Jan/2006:15:04:05 -0700"},
+	},
+	{
+		// Oct  4 12:34:56 (syslog)
+		re:      regexp.MustCompile(`[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`),
+		layouts: []string{"Jan _2 15:04:05"},
+		noYear:  true,
+	},
+}
+
+// Parser reads timestamps from log lines. Lines of one file normally share
+// a format, so the format that matched last is tried first.
+type Parser struct {
+	loc  *time.Location
+	last int
+}
+
+// NewParser returns a Parser that reads timestamps without a zone offset
+// as times in loc
+func NewParser(loc *time.Location) *Parser {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Parser{loc: loc}
+}
+
+// Parse returns the timestamp near the start of line, if it has one
+func (p *Parser) Parse(line string) (time.Time, bool) {
+	if len(line) > searchWidth {
+		line = line[:searchWidth]
+	}
+
+	for i := range formats {
+		idx := (p.last + i) % len(formats)
+		if t, ok := formats[idx].parse(line, p.loc); ok {
+			p.last = idx
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (f format) parse(line string, loc *time.Location) (time.Time, bool) {
+	match := f.re.FindString(line)
+	if match == "" {
+		return time.Time{}, false
+	}
+
+	// Accept both ISO separators and both decimal marks
+	match = strings.Replace(match, "T", " ", 1)
+	match = strings.Replace(match, ",", ".", 1)
+	match = strings.Replace(match, " Z", "Z", 1)
+
+	for _, layout := range f.layouts {
+		t, err := time.ParseInLocation(layout, match, loc)
+		if err != nil {
+			continue
+		}
+		if f.noYear {
+			t = withYear(t, loc)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// withYear places a timestamp without a year in the most recent year that
+// doesn't put it in the future
+func withYear(t time.Time, loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
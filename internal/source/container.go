@@ -0,0 +1,435 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/config"
+)
+
+var pruneContainerOnce sync.Once
+
+// Container reads the logs of a Docker container or Kubernetes pod through
+// docker logs or kubectl logs. It has no files: the path given to each
+// operation is a span of the log made by LogSpan.
+type Container struct {
+	run       Runner
+	transport string // config.TransportDocker or config.TransportKubectl
+	name      string // Container or pod
+	namespace string
+	container string
+	cliHost   string // Where run runs the CLI, to tell cached logs apart
+}
+
+// NewContainer returns a Source for the logs of a container or pod of an
+// app, read by running docker or kubectl with run
+func NewContainer(run Runner, app *config.App, name string) (*Container, error) {
+	if err := checkCLIArgs(
+		"container or pod", name,
+		"namespace", app.Namespace,
+		"container", app.Container,
+	); err != nil {
+		return nil, err
+	}
+	return &Container{
+		run:       run,
+		transport: app.Transport,
+		name:      name,
+		namespace: app.Namespace,
+		container: app.Container,
+		cliHost:   app.CLIHostFor(name),
+	}, nil
+}
+
+// checkCLIArgs rejects names given to docker or kubectl, as pairs of what
+// they are and the name, that the CLI would take for an option
+func checkCLIArgs(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if strings.HasPrefix(pairs[i+1], "-") {
+			return fmt.Errorf("invalid %s %q: must not start with \"-\"", pairs[i], pairs[i+1])
+		}
+	}
+	return nil
+}
+
+// LogSpan returns the path naming the part of a container's log between
+// since and until. A zero time leaves that end open; with both zero the
+// path is the whole log.
+func LogSpan(since, until time.Time) string {
+	parts := []string{"logs"}
+	if !since.IsZero() {
+		parts = append(parts, "since="+since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		parts = append(parts, "until="+until.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(parts, " ")
+}
+
+// OpenSpan reports whether a path made by LogSpan leaves its end open, so
+// that the log may still grow
+func OpenSpan(path string) bool {
+	_, until, err := parseSpan(path)
+	return err == nil && until.IsZero()
+}
+
+// parseSpan reads a path made by LogSpan
+func parseSpan(path string) (since, until time.Time, err error) {
+	fields := strings.Fields(path)
+	if len(fields) == 0 || fields[0] != "logs" {
+		return since, until, fmt.Errorf("invalid log span %q", path)
+	}
+	for _, part := range fields[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid log span %q", path)
+		}
+		switch key {
+		case "since":
+			since = t
+		case "until":
+			until = t
+		default:
+			return since, until, fmt.Errorf("invalid log span %q", path)
+		}
+	}
+	return since, until, nil
+}
+
+// logsCommand returns the docker or kubectl command printing the log in a
+// span, or following it from now on
+func (c *Container) logsCommand(span string, follow bool) ([]string, error) {
+	since, until, err := parseSpan(span)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.transport == config.TransportKubectl {
+		// kubectl has no --until; the viewer trims later lines by timestamp
+		args := append([]string{"kubectl", "logs"}, c.kubectlScope()...)
+		if c.container != "" {
+			args = append(args, "--container", c.container)
+		}
+		if !since.IsZero() {
+			args = append(args, "--since-time="+since.Format(time.RFC3339))
+		}
+		if follow {
+			args = append(args, "--follow", "--tail=0")
+		}
+		return append(args, c.name), nil
+	}
+
+	args := []string{"docker", "logs"}
+	if !since.IsZero() {
+		args = append(args, "--since", since.Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		args = append(args, "--until", until.Format(time.RFC3339))
+	}
+	if follow {
+		args = append(args, "--follow", "--tail", "0")
+	}
+	return append(args, c.name), nil
+}
+
+func (c *Container) kubectlScope() []string {
+	if c.namespace == "" {
+		return nil
+	}
+	return []string{"--namespace", c.namespace}
+}
+
+// Stat checks that the container or pod exists. Its log has no size to
+// report, and is never compressed.
+func (c *Container) Stat(path string) (FileInfo, error) {
+	if compress.IsCompressed(path) {
+		return FileInfo{}, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+
+	cmd := []string{"docker", "inspect", "--type", "container", "--format", "{{.State.Status}}", c.name}
+	if c.transport == config.TransportKubectl {
+		cmd = append(append([]string{"kubectl", "get", "pod"}, c.kubectlScope()...), "--output", "name", c.name)
+	}
+
+	var out bytes.Buffer
+	if err := c.run.Exec(context.Background(), &out, cmd); err != nil {
+		return FileInfo{}, c.cliError(err, out.String())
+	}
+	return FileInfo{Name: c.name, Path: path, ModTime: time.Now()}, nil
+}
+
+// ReadDir fails; container logs are not kept in files
+func (c *Container) ReadDir(dir string) ([]FileInfo, error) {
+	return nil, fmt.Errorf("%s logs have no files to list: %w", c.transport, errors.ErrUnsupported)
+}
+
+// ReadRange copies part of the log in a span to w
+func (c *Container) ReadRange(path string, offset, length int64, w io.Writer) (int64, error) {
+	cmd, err := c.logsCommand(path, false)
+	if err != nil {
+		return 0, err
+	}
+	part := &rangeWriter{w: w, skip: offset, left: length}
+	var out bytes.Buffer
+	if err := c.run.Exec(context.Background(), io.MultiWriter(part, &tailBuffer{buf: &out}), cmd); err != nil {
+		return part.n, c.cliError(err, out.String())
+	}
+	return part.n, part.err
+}
+
+// Follow streams the lines the container writes from now on, whatever
+// offset and inode are; lines written while not following are missed. It returns
+// when ctx is cancelled, or with an error when the container stops.
+func (c *Container) Follow(ctx context.Context, path string, offset int64, _ uint64, events chan<- FollowEvent) error {
+	cmd, err := c.logsCommand(path, true)
+	if err != nil {
+		return err
+	}
+
+	lines := &eventWriter{ctx: ctx, events: events, offset: offset}
+	var out bytes.Buffer
+	err = c.run.Exec(ctx, io.MultiWriter(lines, &tailBuffer{buf: &out}), cmd)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return c.cliError(err, out.String())
+	}
+	return fmt.Errorf("%s stopped writing logs", c.name)
+}
+
+// Grep searches the log in a span with grep, run where the CLI runs so
+// that only matching lines are transferred
+func (c *Container) Grep(ctx context.Context, path string, opts GrepOptions, each func(GrepLine)) error {
+	cmd, err := c.logsCommand(path, false)
+	if err != nil {
+		return err
+	}
+	args, err := GrepArgs(opts)
+	if err != nil {
+		return err
+	}
+
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- ScanGrep(r, each)
+		// Let grep finish rather than block on a full pipe
+		_, _ = io.Copy(io.Discard, r)
+	}()
+	err = c.run.Exec(ctx, w, cmd, append([]string{"grep"}, args...))
+	w.Close()
+	scanErr := <-done
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if scanErr != nil {
+		return fmt.Errorf("grep %s: %w", c.name, scanErr)
+	}
+	var exit *ExitError
+	if errors.As(err, &exit) && exit.Status == 1 && strings.TrimSpace(exit.Stderr) == "" {
+		// grep found nothing
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("grep %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// Fetch writes the log in a span to the cache and returns the copy's path.
+// Logs are read again each time, as there is no telling what changed.
+func (c *Container) Fetch(path string) (string, error) {
+	cmd, err := c.logsCommand(path, false)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := containerCacheDir()
+	if err != nil {
+		return "", err
+	}
+	pruneContainerOnce.Do(func() { pruneLocalCache(dir) })
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.transport, c.cliHost, c.namespace, c.name, c.container, path}, "\x00")))
+	localPath := filepath.Join(dir, hex.EncodeToString(sum[:16])+".log")
+
+	f, err := os.CreateTemp(dir, "fetch-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	var out bytes.Buffer
+	err = c.run.Exec(context.Background(), io.MultiWriter(f, &tailBuffer{buf: &out}), cmd)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", c.cliError(err, out.String())
+	}
+	if err := os.Rename(f.Name(), localPath); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// Close releases the runner
+func (c *Container) Close() error {
+	return c.run.Close()
+}
+
+// KeepAlive probes the runner's connection, if it has one that can go
+// stale, until ctx is cancelled
+func (c *Container) KeepAlive(ctx context.Context, interval time.Duration) error {
+	if conn, ok := c.run.(interface {
+		KeepAlive(context.Context, time.Duration) error
+	}); ok {
+		return conn.KeepAlive(ctx, interval)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// cliError describes a failed docker or kubectl command by the last thing
+// it printed, which is its error message. Missing containers and pods
+// wrap os.ErrNotExist.
+func (c *Container) cliError(err error, output string) error {
+	var exit *ExitError
+	if !errors.As(err, &exit) {
+		return err
+	}
+	msg := strings.TrimSpace(output)
+	if i := strings.LastIndexByte(msg, '\n'); i >= 0 {
+		msg = msg[i+1:]
+	}
+	if msg == "" {
+		return fmt.Errorf("%s %s: %w", c.transport, c.name, err)
+	}
+	if strings.Contains(msg, "No such container") || strings.Contains(msg, "NotFound") {
+		return fmt.Errorf("%s: %w", msg, os.ErrNotExist)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// ListPods returns the names of the pods matching a label selector
+func ListPods(run Runner, namespace, selector string) ([]string, error) {
+	if err := checkCLIArgs("namespace", namespace, "selector", selector); err != nil {
+		return nil, err
+	}
+
+	cmd := []string{"kubectl", "get", "pods"}
+	if namespace != "" {
+		cmd = append(cmd, "--namespace", namespace)
+	}
+	cmd = append(cmd, "--selector", selector, "--output", "name")
+
+	var out bytes.Buffer
+	if err := run.Exec(context.Background(), &out, cmd); err != nil {
+		var exit *ExitError
+		if msg := strings.TrimSpace(out.String()); errors.As(err, &exit) && msg != "" {
+			return nil, fmt.Errorf("listing pods: %s", msg)
+		}
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	var pods []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if name := strings.TrimPrefix(strings.TrimSpace(line), "pod/"); name != "" {
+			pods = append(pods, name)
+		}
+	}
+	return pods, nil
+}
+
+func containerCacheDir() (string, error) {
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "containers")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// tailBuffer keeps the last few KiB written to it, where a failing
+// command's error message is
+type tailBuffer struct {
+	buf *bytes.Buffer
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	const keep = 4 << 10
+	t.buf.Write(p)
+	if extra := t.buf.Len() - keep; extra > 0 {
+		t.buf.Next(extra)
+	}
+	return len(p), nil
+}
+
+// rangeWriter passes on length bytes after skipping offset, discarding
+// the rest
+type rangeWriter struct {
+	w    io.Writer
+	skip int64
+	left int64 // Negative for no limit
+	n    int64
+	err  error
+}
+
+func (r *rangeWriter) Write(p []byte) (int, error) {
+	size := len(p)
+	if r.skip > 0 {
+		drop := min(r.skip, int64(len(p)))
+		r.skip -= drop
+		p = p[drop:]
+	}
+	if r.left >= 0 && int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	if len(p) > 0 && r.err == nil {
+		n, err := r.w.Write(p)
+		r.n += int64(n)
+		r.err = err
+		if r.left >= 0 {
+			r.left -= int64(n)
+		}
+	}
+	return size, nil
+}
+
+// eventWriter sends the complete lines written to it as FollowEvents
+type eventWriter struct {
+	ctx     context.Context
+	events  chan<- FollowEvent
+	offset  int64
+	partial []byte
+}
+
+func (e *eventWriter) Write(p []byte) (int, error) {
+	e.offset += int64(len(p))
+	var lines []string
+	lines, e.partial = splitLines(append(e.partial, p...))
+	if len(lines) == 0 {
+		return len(p), nil
+	}
+	if !send(e.ctx, e.events, FollowEvent{Lines: lines, Offset: e.offset - int64(len(e.partial))}) {
+		return 0, e.ctx.Err()
+	}
+	return len(p), nil
+}
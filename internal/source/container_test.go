@@ -0,0 +1,88 @@
+package source
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+)
+
+// recordRunner records the commands it is asked to run, without running
+// them
+type recordRunner struct {
+	commands [][]string
+}
+
+func (r *recordRunner) Exec(_ context.Context, _ io.Writer, stages ...[]string) error {
+	r.commands = append(r.commands, stages...)
+	return nil
+}
+
+func (r *recordRunner) Close() error { return nil }
+
+func TestNewContainerRejectsOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		container string
+		namespace string
+		app       string // The app's container setting
+		wantErr   string
+	}{
+		{name: "plain names", container: "web-1", namespace: "prod", app: "app"},
+		{name: "dash inside a name", container: "web--1", namespace: "prod-eu"},
+		{name: "container", container: "--privileged", wantErr: `invalid container or pod "--privileged"`},
+		{name: "short option", container: "-f", wantErr: `invalid container or pod "-f"`},
+		{name: "namespace", container: "web", namespace: "--all-namespaces", wantErr: `invalid namespace "--all-namespaces"`},
+		{name: "app container", container: "web", app: "-c", wantErr: `invalid container "-c"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &config.App{Transport: config.TransportKubectl, Namespace: tt.namespace, Container: tt.app}
+			run := &recordRunner{}
+			c, err := NewContainer(run, app, tt.container)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewContainer() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewContainer() error = %v", err)
+			}
+
+			if _, err := c.Stat(LogSpan(time.Time{}, time.Time{})); err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			cmd := run.commands[len(run.commands)-1]
+			if last := cmd[len(cmd)-1]; last != tt.container {
+				t.Errorf("command %q does not end with the pod name", cmd)
+			}
+		})
+	}
+}
+
+func TestListPodsRejectsOptions(t *testing.T) {
+	tests := []struct {
+		namespace string
+		selector  string
+		wantErr   bool
+	}{
+		{namespace: "prod", selector: "app=web"},
+		{namespace: "", selector: "app in (web,api)"},
+		{namespace: "-A", selector: "app=web", wantErr: true},
+		{namespace: "prod", selector: "--all", wantErr: true},
+	}
+	for _, tt := range tests {
+		run := &recordRunner{}
+		_, err := ListPods(run, tt.namespace, tt.selector)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ListPods(%q, %q) error = %v, want error %v", tt.namespace, tt.selector, err, tt.wantErr)
+		}
+		if tt.wantErr && len(run.commands) > 0 {
+			t.Errorf("ListPods(%q, %q) ran %q", tt.namespace, tt.selector, run.commands)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Runner runs commands where an app's containers are: on this machine, or
+// on a server over SSH
+type Runner interface {
+	// Exec runs a command given as separate words, piping the output of
+	// each stage into the next and copying the last stage's to w. The
+	// first stage's stderr goes along with its stdout, the way docker and
+	// kubectl logs replay a container's streams. A failure status of the
+	// last stage is returned as an *ExitError. Exec stops the command when
+	// ctx is cancelled.
+	Exec(ctx context.Context, w io.Writer, stages ...[]string) error
+
+	// Close releases the runner
+	Close() error
+}
+
+// ExitError reports a command run by a Runner that exited with a failure
+// status
+type ExitError struct {
+	Status int
+	Stderr string // What stages after the first wrote to stderr
+}
+
+func (e *ExitError) Error() string {
+	if msg := strings.TrimSpace(e.Stderr); msg != "" {
+		return fmt.Sprintf("exit status %d: %s", e.Status, msg)
+	}
+	return fmt.Sprintf("exit status %d", e.Status)
+}
+
+// Exec runs a command on this machine
+func (l *Local) Exec(ctx context.Context, w io.Writer, stages ...[]string) error {
+	if len(stages) == 0 || slices.ContainsFunc(stages, func(stage []string) bool { return len(stage) == 0 }) {
+		return fmt.Errorf("empty command")
+	}
+
+	var stderr bytes.Buffer
+	cmds := make([]*exec.Cmd, len(stages))
+	var pipes []*os.File
+	closePipes := func() {
+		for _, p := range pipes {
+			_ = p.Close()
+		}
+	}
+	for i, stage := range stages {
+		cmds[i] = exec.CommandContext(ctx, stage[0], stage[1:]...)
+		if i > 0 {
+			cmds[i].Stderr = &stderr
+		}
+	}
+	for i := 0; i < len(cmds)-1; i++ {
+		r, pw, err := os.Pipe()
+		if err != nil {
+			closePipes()
+			return err
+		}
+		pipes = append(pipes, r, pw)
+		cmds[i].Stdout = pw
+		cmds[i+1].Stdin = r
+	}
+	last := cmds[len(cmds)-1]
+	last.Stdout = w
+	if cmds[0].Stderr == nil {
+		cmds[0].Stderr = cmds[0].Stdout
+	}
+
+	for i, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			closePipes()
+			for _, started := range cmds[:i] {
+				_ = started.Process.Kill()
+				_ = started.Wait()
+			}
+			return fmt.Errorf("%s: %w", stages[i][0], err)
+		}
+	}
+	// The children hold their own copies of the pipe ends
+	closePipes()
+
+	var err error
+	for _, cmd := range cmds {
+		err = cmd.Wait()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return &ExitError{Status: exit.ExitCode(), Stderr: stderr.String()}
+	}
+	return err
+}
@@ -0,0 +1,15 @@
+package source
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestLocalExecEmpty(t *testing.T) {
+	for _, stages := range [][][]string{nil, {{}}, {{"true"}, {}}} {
+		if err := NewLocal().Exec(context.Background(), io.Discard, stages...); err == nil {
+			t.Errorf("Exec(%q) accepted an empty command", stages)
+		}
+	}
+}
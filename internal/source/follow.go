@@ -0,0 +1,138 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// FollowInterval is how often a followed file is checked for new data
+const FollowInterval = time.Second
+
+// followChunk caps how much is read from a followed file at once
+const followChunk = 4 << 20
+
+// FollowEvent is a batch of lines appended to a followed file
+type FollowEvent struct {
+	Lines   []string
+	Rotated bool   // The file was rotated or truncated; Lines come from the new file
+	Offset  int64  // Where to resume following after Lines
+	Inode   uint64 // The inode of the file Lines come from, or 0 if unknown
+}
+
+// Tail implements Source.Follow by polling src: when the file is rotated,
+// truncated, or disappears and comes back, following continues from the
+// start of the new file. inode tells files apart when they are replaced,
+// returning 0 when it can't; known is the inode of the file offset was
+// taken from, if it is known, so that a file replaced since then is read
+// from the start. Tail returns when ctx is cancelled or reading fails.
+func Tail(ctx context.Context, src Source, filePath string, offset int64, known uint64,
+	inode func(string) uint64, events chan<- FollowEvent) error {
+	id := known
+	if id == 0 {
+		id = inode(filePath)
+	}
+	missing := false
+	var partial []byte
+	// Looking up the inode may cost a remote command, so it is only
+	// checked again when the file looks different from the last poll
+	lastSize, lastMod := int64(-1), time.Time{}
+
+	ticker := time.NewTicker(FollowInterval)
+	defer ticker.Stop()
+
+	for {
+		info, err := src.Stat(filePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist), errors.Is(err, os.ErrPermission):
+			// Between rotation and the new file appearing, or inaccessible
+			// for now; keep trying like tail -F
+			missing = true
+
+		case err != nil:
+			return err
+
+		default:
+			rotated := missing || info.Size < offset
+			if missing {
+				// The file appeared, or came back as a new file; tell it
+				// apart by its own inode from now on
+				id = inode(filePath)
+			} else if id != 0 && (info.Size != lastSize || !info.ModTime.Equal(lastMod)) {
+				if current := inode(filePath); current != 0 && current != id {
+					rotated = true
+					id = current
+				}
+			}
+			missing = false
+			lastSize, lastMod = info.Size, info.ModTime
+
+			if rotated {
+				offset = 0
+				partial = nil
+			}
+
+			for offset < info.Size {
+				var buf bytes.Buffer
+				n, err := src.ReadRange(filePath, offset, min(info.Size-offset, followChunk), &buf)
+				if err != nil {
+					return err
+				}
+				if n == 0 {
+					break
+				}
+				offset += n
+
+				var lines []string
+				lines, partial = splitLines(append(partial, buf.Bytes()...))
+				if len(lines) > 0 || rotated {
+					if !send(ctx, events, FollowEvent{
+						Lines:   lines,
+						Rotated: rotated,
+						Offset:  offset - int64(len(partial)),
+						Inode:   id,
+					}) {
+						return ctx.Err()
+					}
+					rotated = false
+				}
+			}
+
+			if rotated && !send(ctx, events, FollowEvent{Rotated: true, Offset: offset - int64(len(partial)), Inode: id}) {
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// splitLines returns the complete lines in data and the trailing partial line
+func splitLines(data []byte) ([]string, []byte) {
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, data
+	}
+
+	lines := strings.Split(string(data[:end]), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines, append([]byte(nil), data[end+1:]...)
+}
+
+func send(ctx context.Context, events chan<- FollowEvent, event FollowEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
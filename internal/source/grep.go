@@ -0,0 +1,87 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxGrepLine caps the length of a line read back from grep
+const maxGrepLine = 1 << 20
+
+// GrepOptions controls a search run where the log is
+type GrepOptions struct {
+	Pattern    string // Extended regular expression, or a plain string if Fixed
+	Fixed      bool
+	IgnoreCase bool
+	Before     int // Lines of context before each match
+	After      int // Lines of context after each match
+}
+
+// GrepLine is a line found by Grep
+type GrepLine struct {
+	Number int // Line number in the file, after decompression
+	Text   string
+	Match  bool // False for context lines
+	Break  bool // Separates groups of non-adjacent lines; Number and Text are unset
+}
+
+// GrepArgs returns the grep arguments for a search, ending with the
+// pattern; the file, if any, goes after them
+func GrepArgs(opts GrepOptions) ([]string, error) {
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("empty search pattern")
+	}
+	if opts.Before < 0 || opts.After < 0 {
+		return nil, fmt.Errorf("context line counts must not be negative")
+	}
+
+	args := []string{"-n"}
+	if opts.Fixed {
+		args = append(args, "-F")
+	} else {
+		args = append(args, "-E")
+	}
+	if opts.IgnoreCase {
+		args = append(args, "-i")
+	}
+	if opts.Before > 0 {
+		args = append(args, "-B", strconv.Itoa(opts.Before))
+	}
+	if opts.After > 0 {
+		args = append(args, "-A", strconv.Itoa(opts.After))
+	}
+	return append(args, "-e", opts.Pattern), nil
+}
+
+// ScanGrep reads the output of grep -n, calling each for every line
+func ScanGrep(r io.Reader, each func(GrepLine)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxGrepLine)
+	for scanner.Scan() {
+		if line, ok := parseGrepLine(scanner.Text()); ok {
+			each(line)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseGrepLine reads a line of grep -n output: "12:text" for a match,
+// "12-text" for context and "--" between groups
+func parseGrepLine(s string) (GrepLine, bool) {
+	if s == "--" {
+		return GrepLine{Break: true}, true
+	}
+
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end <= 0 || (s[end] != ':' && s[end] != '-') {
+		return GrepLine{}, false
+	}
+	n, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return GrepLine{}, false
+	}
+	return GrepLine{Number: n, Text: s[end+1:], Match: s[end] == ':'}, true
+}
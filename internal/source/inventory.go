@@ -0,0 +1,76 @@
+package source
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+)
+
+// DatedLog is a rotated log file named after a day
+type DatedLog struct {
+	Date time.Time // Start of the day in the file name
+	FileInfo
+}
+
+// DatedLogs lists the files in src next to logPath whose names match
+// pattern, with {date} standing for a date in dateFormat read in loc.
+// Compressed copies are included; where both a plain and a compressed file
+// exist for a day, the plain one is listed. Files are returned oldest
+// first.
+func DatedLogs(src Source, logPath, pattern, dateFormat string, loc *time.Location) ([]DatedLog, error) {
+	expr, err := datedLogExpr(pattern)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ListFiles(src, path.Dir(logPath), expr)
+	if err != nil {
+		return nil, err
+	}
+
+	re := regexp.MustCompile(expr)
+	byDay := make(map[string]DatedLog)
+	for _, file := range files {
+		match := re.FindStringSubmatch(file.Name)
+		date, err := time.ParseInLocation(dateFormat, match[1], loc)
+		if err != nil {
+			continue
+		}
+
+		day := date.Format("2006-01-02")
+		if seen, ok := byDay[day]; ok && !compress.IsCompressed(seen.Name) {
+			continue
+		}
+		byDay[day] = DatedLog{
+			Date:     time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc),
+			FileInfo: file,
+		}
+	}
+
+	logs := make([]DatedLog, 0, len(byDay))
+	for _, log := range byDay {
+		logs = append(logs, log)
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Date.Before(logs[j].Date) })
+	return logs, nil
+}
+
+// datedLogExpr turns a log pattern such as app-{date}.log into a regular
+// expression capturing the date, allowing a compression suffix
+func datedLogExpr(pattern string) (string, error) {
+	before, after, ok := strings.Cut(pattern, "{date}")
+	if !ok {
+		return "", fmt.Errorf("log pattern %q has no {date} placeholder", pattern)
+	}
+
+	exts := make([]string, len(compress.Extensions))
+	for i, ext := range compress.Extensions {
+		exts[i] = regexp.QuoteMeta(ext)
+	}
+	return "^" + regexp.QuoteMeta(before) + "(.+?)" + regexp.QuoteMeta(after) +
+		"(" + strings.Join(exts, "|") + ")?$", nil
+}
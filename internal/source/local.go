@@ -0,0 +1,247 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/config"
+)
+
+// localCacheMaxAge is how long an unused decompressed copy of a local log
+// is kept
+const localCacheMaxAge = 7 * 24 * time.Hour
+
+var pruneLocalOnce sync.Once
+
+// Local reads logs from this machine's filesystem
+type Local struct{}
+
+// NewLocal returns a Source for files on this machine
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Stat returns information about a local file, following symlinks
+func (l *Local) Stat(path string) (FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return localFileInfo(path, info), nil
+}
+
+// ReadDir lists the entries of a local directory. Symlinks are described
+// by what they point to, when that can be read.
+func (l *Local) ReadDir(dir string) ([]FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(entryPath)
+		if err != nil {
+			if info, err = entry.Info(); err != nil {
+				continue
+			}
+		}
+		result = append(result, localFileInfo(entryPath, info))
+	}
+	return result, nil
+}
+
+// ReadRange copies length bytes of a local file starting at offset to w
+func (l *Local) ReadRange(path string, offset, length int64, w io.Writer) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return io.Copy(w, f)
+	}
+	n, err := io.CopyN(w, f, length)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return n, err
+}
+
+// Follow streams complete lines appended to a local file after offset, the
+// way tail -F does
+func (l *Local) Follow(ctx context.Context, path string, offset int64, inode uint64, events chan<- FollowEvent) error {
+	return Tail(ctx, l, path, offset, inode, localInode, events)
+}
+
+// Inode returns the inode number of a local file, or 0 if it can't be read
+func (l *Local) Inode(path string) uint64 {
+	return localInode(path)
+}
+
+// Grep searches a local file with the system's grep, decompressing it on
+// the way if needed, so patterns mean the same as on a server
+func (l *Local) Grep(ctx context.Context, path string, opts GrepOptions, each func(GrepLine)) error {
+	args, err := GrepArgs(opts)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var in io.Reader = f
+	if ext := compress.Ext(path); ext != "" {
+		r, err := compress.NewReader(f, ext)
+		if err != nil {
+			return fmt.Errorf("grep %s: %w", path, err)
+		}
+		defer r.Close()
+		in = r
+	}
+
+	cmd := exec.CommandContext(ctx, "grep", args...)
+	var stderr bytes.Buffer
+	cmd.Stdin = in
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("grep %s: %w", path, err)
+	}
+
+	scanErr := ScanGrep(stdout, each)
+	if scanErr != nil {
+		// Let grep finish rather than block on a full pipe
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	err = cmd.Wait()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if scanErr != nil {
+		return fmt.Errorf("grep %s: %w", path, scanErr)
+	}
+
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 && strings.TrimSpace(stderr.String()) == "" {
+		// grep found nothing
+		return nil
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("grep %s: %s: %w", path, msg, err)
+		}
+		return fmt.Errorf("grep %s: %w", path, err)
+	}
+	return nil
+}
+
+// Fetch returns the path of a local log, or of a decompressed copy of it
+// in the cache for compressed logs
+func (l *Local) Fetch(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+
+	ext := compress.Ext(path)
+	if ext == "" {
+		return path, nil
+	}
+
+	dir, err := localCacheDir()
+	if err != nil {
+		return "", err
+	}
+	pruneLocalOnce.Do(func() { pruneLocalCache(dir) })
+
+	// A changed file gets a new copy; the old one is pruned in time
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs + "\x00" + strconv.FormatInt(info.Size(), 10) + "\x00" +
+		strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+	localPath := filepath.Join(dir, hex.EncodeToString(sum[:16])+".log")
+
+	if _, err := os.Stat(localPath); err == nil {
+		now := time.Now()
+		_ = os.Chtimes(localPath, now, now)
+		return localPath, nil
+	}
+	if err := compress.DecompressFile(path, localPath, ext); err != nil {
+		return "", fmt.Errorf("failed to decompress %s: %w", filepath.Base(path), err)
+	}
+	return localPath, nil
+}
+
+// Close does nothing; there is nothing to release for local files
+func (l *Local) Close() error {
+	return nil
+}
+
+func localFileInfo(path string, info os.FileInfo) FileInfo {
+	return FileInfo{
+		Name:    info.Name(),
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Mode:    info.Mode(),
+	}
+}
+
+func localCacheDir() (string, error) {
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "local")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// pruneLocalCache removes decompressed copies that have not been used
+// recently
+func pruneLocalCache(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-localCacheMaxAge)
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, e.Name()))
+	}
+}
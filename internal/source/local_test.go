@@ -0,0 +1,240 @@
+package source
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testFile is a file to create for a test, last modified at modTime
+type testFile struct {
+	name    string
+	modTime time.Time
+}
+
+// writeFiles creates files in a new directory, returning the directory
+func writeFiles(t *testing.T, files ...testFile) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(dir, f.name)
+		if err := os.WriteFile(p, []byte(f.name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if !f.modTime.IsZero() {
+			if err := os.Chtimes(p, f.modTime, f.modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dir
+}
+
+// names returns the base names of paths
+func names(paths []string) []string {
+	result := make([]string, len(paths))
+	for i, p := range paths {
+		result[i] = filepath.Base(p)
+	}
+	return result
+}
+
+func TestLocalLocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		log     string
+		locator func(dir string) Locator
+		want    []string // nil when the log should not be found
+	}{
+		{name: "plain log", files: []string{"app.log", "app.log.gz"}, log: "app.log", want: []string{"app.log"}},
+		{name: "compressed since", files: []string{"app.log.gz"}, log: "app.log", want: []string{"app.log.gz"}},
+		{name: "other compression", files: []string{"app.log.zst"}, log: "app.log", want: []string{"app.log.zst"}},
+		{name: "asked for compressed", files: []string{"app.log.gz"}, log: "app.log.gz", want: []string{"app.log.gz"}},
+		{name: "missing", files: []string{"other.log"}, log: "app.log"},
+		{
+			name:  "existing files in order",
+			files: []string{"app-2.log", "app-1.log.gz"},
+			log:   "app.log",
+			locator: func(dir string) Locator {
+				return LocateExisting([]string{
+					filepath.Join(dir, "app-1.log"),
+					filepath.Join(dir, "app-missing.log"),
+					filepath.Join(dir, "app-2.log"),
+				})
+			},
+			want: []string{"app-1.log.gz", "app-2.log"},
+		},
+		{
+			name:  "no existing files",
+			files: []string{"app.log"},
+			log:   "app.log",
+			locator: func(dir string) Locator {
+				return LocateExisting([]string{filepath.Join(dir, "app-1.log")})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []testFile
+			for _, name := range tt.files {
+				files = append(files, testFile{name: name})
+			}
+			dir := writeFiles(t, files...)
+
+			var locator Locator
+			if tt.locator != nil {
+				locator = tt.locator(dir)
+			}
+			got, err := Locate(NewLocal(), "localhost", filepath.Join(dir, tt.log), locator)
+			if tt.want == nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("Locate() = %q, %v, want os.ErrNotExist", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Locate() error = %v", err)
+			}
+			if !slices.Equal(names(got), tt.want) {
+				t.Errorf("Locate() = %q, want %q", names(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalGenerations(t *testing.T) {
+	dir := writeFiles(t,
+		testFile{name: "app.log"},
+		testFile{name: "app.log.1"},
+		testFile{name: "app.log.2.gz"},
+		testFile{name: "app.log.3"},
+		testFile{name: "app.log.3.gz"}, // Plain copy of the same generation wins
+		testFile{name: "app.log.10.xz"},
+		testFile{name: "app.log.0"},   // Not a generation
+		testFile{name: "app.log.old"}, // Not numbered
+		testFile{name: "app.log-1"},
+		testFile{name: "other.log.1"},
+	)
+	if err := os.Mkdir(filepath.Join(dir, "app.log.4"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	gens, err := Generations(NewLocal(), filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		index int
+		name  string
+	}{
+		{0, "app.log"},
+		{1, "app.log.1"},
+		{2, "app.log.2.gz"},
+		{3, "app.log.3"},
+		{10, "app.log.10.xz"},
+	}
+	if len(gens) != len(want) {
+		t.Fatalf("Generations() returned %d generations %v, want %d", len(gens), gens, len(want))
+	}
+	for i, w := range want {
+		if gens[i].Index != w.index || gens[i].Name != w.name {
+			t.Errorf("generation %d = %d %s, want %d %s", i, gens[i].Index, gens[i].Name, w.index, w.name)
+		}
+	}
+
+	if _, err := Generations(NewLocal(), filepath.Join(dir, "missing", "app.log")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Generations() of a missing directory error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestLocalLocateWindow(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	// Rotated daily just after midnight: app.log.N holds the lines of
+	// March 10-N and was last written to just before rotation
+	dir := writeFiles(t,
+		testFile{name: "app.log", modTime: day(10, 12)},
+		testFile{name: "app.log.1", modTime: day(10, 0)},
+		testFile{name: "app.log.2.gz", modTime: day(9, 0)},
+		testFile{name: "app.log.3.gz", modTime: day(8, 0)},
+	)
+	logPath := filepath.Join(dir, "app.log")
+
+	tests := []struct {
+		name         string
+		since, until time.Time
+		want         []string // nil when nothing should be found
+	}{
+		{name: "open window", since: day(9, 12), want: []string{"app.log.1", "app.log"}},
+		{name: "one old day", since: day(8, 6), until: day(8, 18), want: []string{"app.log.2.gz"}},
+		{name: "across days", since: day(7, 6), until: day(9, 6), want: []string{"app.log.3.gz", "app.log.2.gz", "app.log.1"}},
+		{name: "everything", since: day(1, 0), want: []string{"app.log.3.gz", "app.log.2.gz", "app.log.1", "app.log"}},
+		{name: "after the last write", since: day(11, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Locate(NewLocal(), "localhost", logPath, LocateWindow(logPath, tt.since, tt.until))
+			if tt.want == nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("Locate() = %q, %v, want os.ErrNotExist", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Locate() error = %v", err)
+			}
+			if !slices.Equal(names(got), tt.want) {
+				t.Errorf("Locate() = %q, want %q", names(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalDatedLogs(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	dir := writeFiles(t,
+		testFile{name: "app.log"},
+		testFile{name: "app-2024-03-08.log.gz"},
+		testFile{name: "app-2024-03-09.log"},
+		testFile{name: "app-2024-03-09.log.gz"}, // Plain copy of the same day wins
+		testFile{name: "app-2024-03-10.log.zst"},
+		testFile{name: "app-2024-03-07.log"},
+		testFile{name: "app-bad-date.log"},
+		testFile{name: "app-2024-03-11.log.bak"},
+		testFile{name: "other-2024-03-09.log"},
+	)
+
+	logs, err := DatedLogs(NewLocal(), filepath.Join(dir, "app.log"), "app-{date}.log", "2006-01-02", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		day  int
+		name string
+	}{
+		{7, "app-2024-03-07.log"},
+		{8, "app-2024-03-08.log.gz"},
+		{9, "app-2024-03-09.log"},
+		{10, "app-2024-03-10.log.zst"},
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("DatedLogs() returned %d logs %v, want %d", len(logs), logs, len(want))
+	}
+	for i, w := range want {
+		wantDate := time.Date(2024, 3, w.day, 0, 0, 0, 0, loc)
+		if !logs[i].Date.Equal(wantDate) || logs[i].Name != w.name {
+			t.Errorf("log %d = %s %s, want %s %s", i, logs[i].Date, logs[i].Name, wantDate, w.name)
+		}
+	}
+
+	if _, err := DatedLogs(NewLocal(), filepath.Join(dir, "app.log"), "app.log", "2006-01-02", loc); err == nil {
+		t.Error("DatedLogs() accepted a pattern without {date}")
+	}
+}
@@ -0,0 +1,164 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+)
+
+// Generation is one file of a log rotated by number, as logrotate does
+// without dateext: the current log is generation 0, app.log.1 is
+// generation 1, app.log.2.gz generation 2 and so on
+type Generation struct {
+	Index int
+	FileInfo
+}
+
+// Generations lists the generations of logPath in src, newest first.
+// Where logrotate left both a plain and a compressed copy of one
+// generation, the plain one is listed.
+func Generations(src Source, logPath string) ([]Generation, error) {
+	entries, err := src.ReadDir(path.Dir(logPath))
+	if err != nil {
+		return nil, err
+	}
+
+	base := path.Base(logPath)
+	byIndex := make(map[int]Generation)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		index := 0
+		if entry.Name != base {
+			rest, ok := strings.CutPrefix(entry.Name, base+".")
+			if !ok {
+				continue
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(rest, compress.Ext(rest)))
+			if err != nil || n < 1 {
+				continue
+			}
+			index = n
+		}
+
+		if seen, ok := byIndex[index]; ok && !compress.IsCompressed(seen.Name) {
+			continue
+		}
+		byIndex[index] = Generation{Index: index, FileInfo: entry}
+	}
+
+	gens := make([]Generation, 0, len(byIndex))
+	for _, gen := range byIndex {
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i].Index < gens[j].Index })
+	return gens, nil
+}
+
+// GenerationForDate picks the generation holding a day's lines: the oldest
+// one still written to on or after that day began, in date's location.
+// Each generation is last modified just before it is rotated, so with
+// daily rotation this is the file for that day.
+func GenerationForDate(gens []Generation, date time.Time) (Generation, bool) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	for i := len(gens) - 1; i >= 0; i-- {
+		if !gens[i].ModTime.Before(day) {
+			return gens[i], true
+		}
+	}
+	return Generation{}, false
+}
+
+// LocateGeneration returns a Locator that finds the given generation of
+// logPath in each source
+func LocateGeneration(logPath string, index int) Locator {
+	return func(src Source, _ string) ([]string, error) {
+		gens, err := Generations(src, logPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, gen := range gens {
+			if gen.Index == index {
+				return []string{gen.Path}, nil
+			}
+		}
+		return nil, fmt.Errorf("no generation %d of %s: %w", index, logPath, os.ErrNotExist)
+	}
+}
+
+// LocateDate returns a Locator that finds the generation of logPath
+// holding a day's lines in each source. Servers may rotate at different
+// times, so each one is looked up separately.
+func LocateDate(logPath string, date time.Time) Locator {
+	return func(src Source, _ string) ([]string, error) {
+		gens, err := Generations(src, logPath)
+		if err != nil {
+			return nil, err
+		}
+		gen, ok := GenerationForDate(gens, date)
+		if !ok {
+			return nil, fmt.Errorf("no generation of %s covers %s: %w", logPath, date.Format("2006-01-02"), os.ErrNotExist)
+		}
+		return []string{gen.Path}, nil
+	}
+}
+
+// LocateWindow returns a Locator that finds every generation of logPath
+// written to between since and until, oldest first. A generation holds the
+// lines written after the one before it was last modified. A zero until
+// leaves the window open.
+func LocateWindow(logPath string, since, until time.Time) Locator {
+	return func(src Source, _ string) ([]string, error) {
+		gens, err := Generations(src, logPath)
+		if err != nil {
+			return nil, err
+		}
+
+		var paths []string
+		for i := len(gens) - 1; i >= 0; i-- {
+			if gens[i].ModTime.Before(since) {
+				continue
+			}
+			if !until.IsZero() && i < len(gens)-1 && gens[i+1].ModTime.After(until) {
+				break
+			}
+			paths = append(paths, gens[i].Path)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no generation of %s covers the time window: %w", logPath, os.ErrNotExist)
+		}
+		return paths, nil
+	}
+}
+
+// LocateExisting returns a Locator that finds those of paths, or
+// compressed copies of them, that are in each source, keeping their order
+func LocateExisting(paths []string) Locator {
+	return func(src Source, _ string) ([]string, error) {
+		var found []string
+		for _, p := range paths {
+			f, err := FindLog(src, p)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, f)
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("none of %d log files found: %w", len(paths), os.ErrNotExist)
+		}
+		return found, nil
+	}
+}
@@ -0,0 +1,146 @@
+// Package source reads logs wherever an app keeps them: on its servers
+// over SSH, on this machine, or in its containers through docker or kubectl
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+)
+
+// Source is where an app's log files are read from. Fetching, following,
+// searching and listing logs are all built on these operations, so they
+// work the same over any transport.
+type Source interface {
+	// Stat returns information about a file, following symlinks
+	Stat(path string) (FileInfo, error)
+
+	// ReadDir lists the entries of a directory
+	ReadDir(dir string) ([]FileInfo, error)
+
+	// ReadRange copies length bytes of a file starting at offset to w. A
+	// negative length reads to the end of the file. It returns the number
+	// of bytes copied, which is less than length if the file is shorter.
+	ReadRange(path string, offset, length int64, w io.Writer) (int64, error)
+
+	// Follow streams complete lines appended to a file after offset, the
+	// way tail -F does, until ctx is cancelled or reading fails. inode is
+	// the inode of the file offset belongs to, or 0 if unknown; if path
+	// has since been replaced by another file, that one is followed from
+	// its start.
+	Follow(ctx context.Context, path string, offset int64, inode uint64, events chan<- FollowEvent) error
+
+	// Grep searches a file, decompressing it if needed, calling each for
+	// every matching and context line. Finding nothing is not an error.
+	Grep(ctx context.Context, path string, opts GrepOptions, each func(GrepLine)) error
+
+	// Fetch returns the path of a local file holding the plain text of a
+	// log, which must be treated as read-only
+	Fetch(path string) (string, error)
+
+	// Close releases the source
+	Close() error
+}
+
+// Locator finds the files of a log in a source, given as its host in the
+// app, oldest first. It is for logs whose names differ between servers,
+// such as numbered rotations, or that span several files.
+type Locator func(src Source, host string) ([]string, error)
+
+// FileInfo describes a file in a source
+type FileInfo struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Mode    os.FileMode
+}
+
+// IsDir reports whether the file is a directory
+func (f FileInfo) IsDir() bool {
+	return f.Mode.IsDir()
+}
+
+// FileExists checks if a file exists in src
+func FileExists(src Source, path string) (bool, error) {
+	info, err := src.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return !info.IsDir(), nil
+}
+
+// FindLog returns the path a log is stored under in src: logPath itself
+// or, once logrotate has compressed it, logPath with one of
+// compress.Extensions added. The error wraps os.ErrNotExist if neither is
+// there.
+func FindLog(src Source, logPath string) (string, error) {
+	_, err := src.Stat(logPath)
+	if !errors.Is(err, os.ErrNotExist) || compress.IsCompressed(logPath) {
+		return logPath, err
+	}
+
+	for _, ext := range compress.Extensions {
+		if _, statErr := src.Stat(logPath + ext); statErr == nil {
+			return logPath + ext, nil
+		}
+	}
+	return logPath, err
+}
+
+// ListFiles lists files whose names match a regular expression in a
+// directory of src, sorted by name
+func ListFiles(src Source, dir, pattern string) ([]FileInfo, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+	}
+
+	entries, err := src.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []FileInfo
+	for _, entry := range entries {
+		if !entry.IsDir() && re.MatchString(entry.Name) {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// Locate finds the files of a log in src with locator, or logPath or a
+// compressed copy of it when locator is nil. The error wraps
+// os.ErrNotExist if nothing is found.
+func Locate(src Source, host, logPath string, locator Locator) ([]string, error) {
+	if locator == nil {
+		found, err := FindLog(src, logPath)
+		if err != nil {
+			return nil, err
+		}
+		return []string{found}, nil
+	}
+
+	found, err := locator(src, host)
+	if err == nil && len(found) == 0 {
+		err = fmt.Errorf("%s: %w", logPath, os.ErrNotExist)
+	}
+	return found, err
+}
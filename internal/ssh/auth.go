@@ -0,0 +1,234 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/vault"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Authentication method names accepted in the user configuration
+const (
+	AuthAgent    = "agent"
+	AuthKey      = "key"
+	AuthPassword = "password"
+)
+
+// DefaultAuthMethods is the fallback order used when a user has none configured
+var DefaultAuthMethods = []string{AuthAgent, AuthKey, AuthPassword}
+
+// defaultKeyFiles are tried when key auth is enabled without explicit key files
+var defaultKeyFiles = []string{
+	"~/.ssh/id_ed25519",
+	"~/.ssh/id_ecdsa",
+	"~/.ssh/id_rsa",
+}
+
+// Auth describes how to authenticate an SSH connection
+type Auth struct {
+	Methods    []string // Tried in order; defaults to DefaultAuthMethods
+	KeyFiles   []string // Private keys for the "key" method
+	Password   string
+	Passphrase string // Used for encrypted private keys
+}
+
+// AuthForUser builds the auth settings for a configured user, pulling
+// the password and key passphrase from the system keyring
+func AuthForUser(user *config.User) (Auth, error) {
+	auth := Auth{
+		Methods:  user.Auth,
+		KeyFiles: user.KeyFiles,
+	}
+	if len(auth.Methods) == 0 {
+		auth.Methods = DefaultAuthMethods
+	}
+
+	for _, method := range auth.Methods {
+		switch method {
+		case AuthAgent, AuthKey, AuthPassword:
+		default:
+			return Auth{}, fmt.Errorf("unknown auth method %q for user %s", method, user.Name)
+		}
+	}
+
+	creds, err := vault.Get(user.ID)
+	if err == nil {
+		auth.Password = creds.Password
+	} else if len(auth.Methods) == 1 && auth.Methods[0] == AuthPassword {
+		return Auth{}, fmt.Errorf("failed to get credentials for user %s: %w", user.Name, err)
+	}
+
+	if passphrase, err := vault.GetPassphrase(user.ID); err == nil {
+		auth.Passphrase = passphrase
+	}
+
+	return auth, nil
+}
+
+// ConnectUser establishes an SSH connection using a configured user
+func ConnectUser(host string, user *config.User) (*Client, error) {
+	auth, err := AuthForUser(user)
+	if err != nil {
+		return nil, err
+	}
+	return ConnectWithAuth(host, user.Username, auth)
+}
+
+// authMethods turns the auth settings into ssh.AuthMethods in the configured
+// order. The returned cleanup func releases the agent connection, if any.
+func (a Auth) authMethods() ([]ssh.AuthMethod, func(), error) {
+	methods := a.Methods
+	if len(methods) == 0 {
+		methods = DefaultAuthMethods
+	}
+
+	var (
+		result    []ssh.AuthMethod
+		signers   []ssh.Signer
+		problems  []string
+		agentConn net.Conn
+		keyIndex  = -1
+	)
+
+	cleanup := func() {
+		if agentConn != nil {
+			_ = agentConn.Close()
+		}
+	}
+
+	// The ssh package only tries each method name once, so agent and key
+	// signers share a single publickey method placed at the first of them
+	addPublicKeys := func() {
+		if keyIndex < 0 {
+			keyIndex = len(result)
+			result = append(result, nil)
+		}
+	}
+
+	for _, method := range methods {
+		switch method {
+		case AuthAgent:
+			socket := os.Getenv("SSH_AUTH_SOCK")
+			if socket == "" {
+				problems = append(problems, "ssh-agent: SSH_AUTH_SOCK is not set")
+				continue
+			}
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("ssh-agent: %v", err))
+				continue
+			}
+			agentConn = conn
+			agentSigners, err := agent.NewClient(conn).Signers()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("ssh-agent: %v", err))
+				continue
+			}
+			if len(agentSigners) == 0 {
+				continue
+			}
+			signers = append(signers, agentSigners...)
+			addPublicKeys()
+
+		case AuthKey:
+			keyFiles := a.KeyFiles
+			explicit := len(keyFiles) > 0
+			if !explicit {
+				keyFiles = defaultKeyFiles
+			}
+			for _, keyFile := range keyFiles {
+				signer, err := loadKey(keyFile, a.Passphrase)
+				if err != nil {
+					if !explicit && errors.Is(err, os.ErrNotExist) {
+						continue
+					}
+					problems = append(problems, err.Error())
+					continue
+				}
+				signers = append(signers, signer)
+				addPublicKeys()
+			}
+
+		case AuthPassword:
+			if a.Password == "" {
+				continue
+			}
+			password := a.Password
+			result = append(result,
+				ssh.Password(password),
+				ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+					answers := make([]string, len(questions))
+					for i := range questions {
+						answers[i] = password
+					}
+					return answers, nil
+				}),
+			)
+
+		default:
+			problems = append(problems, fmt.Sprintf("unknown auth method %q", method))
+		}
+	}
+
+	if keyIndex >= 0 {
+		result[keyIndex] = ssh.PublicKeys(signers...)
+	}
+
+	if len(result) == 0 {
+		cleanup()
+		if len(problems) == 0 {
+			return nil, nil, fmt.Errorf("no usable authentication methods")
+		}
+		return nil, nil, fmt.Errorf("no usable authentication methods: %s", strings.Join(problems, "; "))
+	}
+
+	return result, cleanup, nil
+}
+
+// loadKey reads a private key file, decrypting it with passphrase if needed
+func loadKey(path, passphrase string) (ssh.Signer, error) {
+	path = expandHome(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	if err == nil {
+		return signer, nil
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("key %s is encrypted and no passphrase is stored", path)
+	}
+
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
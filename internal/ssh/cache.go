@@ -0,0 +1,271 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+)
+
+// headSize is how much of the start of a file is compared to tell whether
+// the remote file is still the one the local copy was taken from
+const headSize = 4096
+
+// cacheMaxAge is how long an unused cached download is kept
+const cacheMaxAge = 30 * 24 * time.Hour
+
+var pruneOnce sync.Once
+
+// cacheLocks serializes syncs of the same cached file within this process;
+// a lock on a file next to it does so between processes
+var cacheLocks sync.Map
+
+// cacheEntry records what was fetched for one server and path
+type cacheEntry struct {
+	Host    string    `json:"host"`
+	Via     string    `json:"via,omitempty"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Inode   uint64    `json:"inode,omitempty"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// SyncFile brings the local cached copy of a remote file up to date and
+// returns its path, with the inode of the remote file it now matches, or 0
+// if unknown. Only bytes appended since the last sync are fetched, and
+// an interrupted transfer resumes where it stopped. If the remote file was
+// rotated, truncated or rewritten the copy is discarded and fetched again.
+// Copies are kept per server and route, so the same private address behind
+// different jump hosts gets its own. The returned file is shared between
+// runs and must be treated as read-only.
+//
+// Compressed files (see compress.Extensions) are fetched as they are and
+// decompressed locally, so the returned path always holds plain text.
+func (c *Client) SyncFile(remotePath string) (string, uint64, error) {
+	dir, err := downloadsDir()
+	if err != nil {
+		return "", 0, err
+	}
+	pruneOnce.Do(func() { pruneCache(dir) })
+
+	key := cacheKey(c.host, c.via, remotePath)
+	lock, _ := cacheLocks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	unlock, err := lockFile(filepath.Join(dir, key+".lock"))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to lock cached copy: %w", err)
+	}
+	defer unlock()
+
+	localPath := filepath.Join(dir, key+".log")
+	metaPath := filepath.Join(dir, key+".json")
+
+	// The bytes as they are on the server
+	ext := compress.Ext(remotePath)
+	rawPath := localPath
+	if ext != "" {
+		rawPath = filepath.Join(dir, key+ext)
+	}
+
+	info, err := c.Stat(remotePath)
+	if err != nil {
+		return "", 0, err
+	}
+	inode := c.Inode(remotePath)
+
+	entry := loadCacheEntry(metaPath)
+	localSize := fileSize(rawPath)
+
+	if !c.sameFile(remotePath, rawPath, localSize, info, inode, entry) {
+		localSize = 0
+	}
+	changed := localSize != info.Size
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if localSize == 0 {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(rawPath, flags, 0600)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if info.Size > localSize {
+		_, err = c.ReadRange(remotePath, localSize, info.Size-localSize, f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	if ext != "" && (changed || !exists(localPath)) {
+		if err := compress.DecompressFile(rawPath, localPath, ext); err != nil {
+			// Don't leave the copy of an older download to be reused
+			_ = os.Remove(localPath)
+			return "", 0, fmt.Errorf("failed to decompress %s: %w", path.Base(remotePath), err)
+		}
+	}
+
+	synced := cacheEntry{
+		Host:    c.host,
+		Via:     c.via,
+		Path:    remotePath,
+		Size:    fileSize(rawPath),
+		Inode:   inode,
+		ModTime: info.ModTime,
+	}
+	if err := saveCacheEntry(metaPath, synced); err != nil {
+		return "", 0, err
+	}
+
+	return localPath, inode, nil
+}
+
+// sameFile reports whether the local copy is a prefix of the remote file.
+// A changed inode, a remote file smaller than the local copy, a changed
+// modification time at the size last synced, or different leading bytes
+// all mean the file was rotated or rewritten.
+func (c *Client) sameFile(remotePath, localPath string, localSize int64, info source.FileInfo, inode uint64, entry *cacheEntry) bool {
+	if localSize == 0 {
+		return false
+	}
+	if entry != nil && entry.Inode != 0 && inode != 0 && entry.Inode != inode {
+		return false
+	}
+	if info.Size < localSize {
+		return false
+	}
+	if entry != nil && entry.Size == info.Size && !entry.ModTime.Equal(info.ModTime) {
+		// Appending would have grown it, so it was written over in place
+		return false
+	}
+
+	n := min(localSize, headSize)
+	localHead := make([]byte, n)
+	f, err := os.Open(localPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if _, err := io.ReadFull(f, localHead); err != nil {
+		return false
+	}
+
+	var remoteHead bytes.Buffer
+	if _, err := c.ReadRange(remotePath, 0, n, &remoteHead); err != nil {
+		return false
+	}
+	return bytes.Equal(localHead, remoteHead.Bytes())
+}
+
+// Inode returns the inode number of a remote file, or 0 when the server
+// does not allow running stat
+func (c *Client) Inode(remotePath string) uint64 {
+	output, err := c.run(command("stat", "-L", "-c", "%i", "--", remotePath))
+	if err != nil {
+		return 0
+	}
+	inode, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return inode
+}
+
+func downloadsDir() (string, error) {
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "downloads")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// cacheKey names the cached copy of a file on a server reached through the
+// jump hosts in via. Direct connections keep the names they always had.
+func cacheKey(host, via, remotePath string) string {
+	id := host + "\x00" + remotePath
+	if via != "" {
+		id = via + "\x00" + id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+func loadCacheEntry(metaPath string) *cacheEntry {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+func saveCacheEntry(metaPath string, entry cacheEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, data, 0600)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// pruneCache removes cached downloads that have not been synced recently
+func pruneCache(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-cacheMaxAge)
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		key := strings.TrimSuffix(e.Name(), ".json")
+		_ = os.Remove(filepath.Join(dir, key+".log"))
+		for _, ext := range compress.Extensions {
+			_ = os.Remove(filepath.Join(dir, key+ext))
+		}
+		_ = os.Remove(filepath.Join(dir, key+".json"))
+		_ = os.Remove(filepath.Join(dir, key+".lock"))
+	}
+}
@@ -0,0 +1,89 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// remotePrograms are the only programs logx runs on a server. Anything
+// else is refused before it reaches the remote shell.
+var remotePrograms = map[string]bool{
+	"stat":  true,
+	"find":  true,
+	"tail":  true,
+	"grep":  true,
+	"zgrep": true,
+	"bzip2": true,
+	"xz":    true,
+	"zstd":  true,
+
+	// For apps whose logs come from containers
+	"docker":  true,
+	"kubectl": true,
+}
+
+// remoteCommand is a command to run on a server, kept as separate words
+// until it is sent so that every argument is quoted. Stages are joined
+// with pipes.
+type remoteCommand struct {
+	stages   [][]string
+	combined bool // The first stage's stderr goes along with its stdout
+}
+
+// command starts a remote command running program with args
+func command(program string, args ...string) remoteCommand {
+	return remoteCommand{}.pipe(program, args...)
+}
+
+// pipe returns the command with its output piped into program
+func (c remoteCommand) pipe(program string, args ...string) remoteCommand {
+	stages := append([][]string(nil), c.stages...)
+	stage := append([]string{program}, args...)
+	return remoteCommand{stages: append(stages, stage), combined: c.combined}
+}
+
+// combineStderr returns the command with the first stage's stderr sent
+// along with its stdout
+func (c remoteCommand) combineStderr() remoteCommand {
+	c.combined = true
+	return c
+}
+
+// String shows the command the way it is sent to the server
+func (c remoteCommand) String() string {
+	stages := make([]string, len(c.stages))
+	for i, stage := range c.stages {
+		words := make([]string, len(stage))
+		for j, word := range stage {
+			words[j] = shellWord(word)
+		}
+		stages[i] = strings.Join(words, " ")
+	}
+	if c.combined && len(stages) > 0 {
+		stages[0] += " 2>&1"
+	}
+	return strings.Join(stages, " | ")
+}
+
+// build checks every program against remotePrograms and returns the
+// command line to send
+func (c remoteCommand) build() (string, error) {
+	if len(c.stages) == 0 {
+		return "", fmt.Errorf("empty remote command")
+	}
+	for _, stage := range c.stages {
+		if !remotePrograms[stage[0]] {
+			return "", fmt.Errorf("remote command %q is not allowed", stage[0])
+		}
+	}
+	return c.String(), nil
+}
+
+// shellWord quotes a word for the remote shell, leaving words made only of
+// characters the shell treats literally as they are, for readability
+func shellWord(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./+,:=@") == "" {
+		return s
+	}
+	return shellQuote(s)
+}
@@ -0,0 +1,199 @@
+package ssh
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// hostileWords are arguments the remote shell must see unchanged
+var hostileWords = []string{
+	"",
+	"plain",
+	"two words",
+	"it's",
+	`say "hi"`,
+	"'''",
+	"$(touch /tmp/pwned)",
+	"`touch /tmp/pwned`",
+	"$HOME",
+	"a;b|c&d",
+	"*.log",
+	"line\nbreak",
+	`back\slash`,
+	"-v",
+}
+
+func TestShellWord(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"plain", "plain"},
+		{"/var/log/app.log", "/var/log/app.log"},
+		{"--since=2024-01-02T03:04:05Z", "--since=2024-01-02T03:04:05Z"},
+		{"", "''"},
+		{"two words", "'two words'"},
+		{"it's", `'it'\''s'`},
+		{`say "hi"`, `'say "hi"'`},
+		{"$(id)", "'$(id)'"},
+		{"`id`", "'`id`'"},
+		{"$HOME", "'$HOME'"},
+		{"a;b", "'a;b'"},
+		{"*.log", "'*.log'"},
+	}
+	for _, tt := range tests {
+		if got := shellWord(tt.word); got != tt.want {
+			t.Errorf("shellWord(%q) = %s, want %s", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"", "''"},
+		{"plain", "'plain'"},
+		{"'", `''\'''`},
+		{"a'b'c", `'a'\''b'\''c'`},
+		{"$(id) `id`", "'$(id) `id`'"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.word); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.word, got, tt.want)
+		}
+	}
+}
+
+// TestShellWordRoundTrip checks that a POSIX shell reads every quoted word
+// back as it was, without expanding or running anything
+func TestShellWordRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh to run commands with")
+	}
+	for _, word := range hostileWords {
+		out, err := exec.Command(sh, "-c", "printf '%s' "+shellWord(word)).Output()
+		if err != nil {
+			t.Errorf("sh failed for %q: %v", word, err)
+			continue
+		}
+		if string(out) != word {
+			t.Errorf("sh read %q back as %q", word, out)
+		}
+	}
+}
+
+func TestRemoteCommandBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     remoteCommand
+		want    string
+		wantErr string
+	}{
+		{
+			name: "plain words",
+			cmd:  command("stat", "-L", "-c", "%s", "--", "/var/log/app.log"),
+			want: "stat -L -c '%s' -- /var/log/app.log",
+		},
+		{
+			name: "spaces and quotes",
+			cmd:  command("grep", "-e", `it's "here"`, "--", "/var/log/my app.log"),
+			want: `grep -e 'it'\''s "here"' -- '/var/log/my app.log'`,
+		},
+		{
+			name: "command substitution",
+			cmd:  command("grep", "-e", "$(rm -rf /)", "--", "`reboot`"),
+			want: "grep -e '$(rm -rf /)' -- '`reboot`'",
+		},
+		{
+			name: "empty argument",
+			cmd:  command("grep", "-e", "", "--", "app.log"),
+			want: "grep -e '' -- app.log",
+		},
+		{
+			name: "pipe",
+			cmd:  command("xz", "-dc", "--", "app.log.xz").pipe("grep", "-n", "a|b"),
+			want: "xz -dc -- app.log.xz | grep -n 'a|b'",
+		},
+		{
+			name: "stderr combined on the first stage only",
+			cmd:  command("docker", "logs", "web").combineStderr().pipe("tail", "-c", "100"),
+			want: "docker logs web 2>&1 | tail -c 100",
+		},
+		{
+			name:    "empty command",
+			cmd:     remoteCommand{},
+			wantErr: "empty remote command",
+		},
+		{
+			name:    "program not allowed",
+			cmd:     command("rm", "-rf", "/"),
+			wantErr: `remote command "rm" is not allowed`,
+		},
+		{
+			name:    "program not allowed in a later stage",
+			cmd:     command("tail", "app.log").pipe("sh", "-c", "id"),
+			wantErr: `remote command "sh" is not allowed`,
+		},
+		{
+			name:    "program given with a path",
+			cmd:     command("/bin/grep", "x"),
+			wantErr: `remote command "/bin/grep" is not allowed`,
+		},
+		{
+			name:    "program smuggled into its name",
+			cmd:     command("grep; rm -rf /", "x"),
+			wantErr: "is not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cmd.build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("build() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("build() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("build() = %s\nwant      %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRemoteCommandArgs checks that a built command hands its arguments to
+// the program unchanged, whatever they hold
+func TestRemoteCommandArgs(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh to run commands with")
+	}
+	line, err := command("grep", hostileWords...).build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stand in for grep with a function printing each argument followed by
+	// a NUL, so that words holding newlines survive
+	script := `grep() { for a in "$@"; do printf '%s\0' "$a"; done; }; ` + line
+	out, err := exec.Command(sh, "-c", script).Output()
+	if err != nil {
+		t.Fatalf("sh failed: %v", err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if len(got) != len(hostileWords) {
+		t.Fatalf("got %d arguments %q, want %d", len(got), got, len(hostileWords))
+	}
+	for i, word := range hostileWords {
+		if got[i] != word {
+			t.Errorf("argument %d = %q, want %q", i, got[i], word)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jatsandaruwan/logx/internal/source"
+	"golang.org/x/crypto/ssh"
+)
+
+// run executes a command on the remote server and returns its stdout. A
+// failing command's stderr is turned into an error, with "No such file" and
+// "Permission denied" mapped to os.ErrNotExist and os.ErrPermission.
+func (c *Client) run(cmd remoteCommand) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := c.stream(cmd, &stdout); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// stream executes a command on the remote server, copying its stdout to w
+func (c *Client) stream(cmd remoteCommand, w io.Writer) error {
+	line, err := cmd.build()
+	if err != nil {
+		return err
+	}
+
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer func(session *ssh.Session) {
+		err := session.Close()
+		if err != nil {
+			_ = fmt.Errorf("an error occurred while closing the session %w", err)
+		}
+	}(session)
+
+	var stderr bytes.Buffer
+	session.Stdout = w
+	session.Stderr = &stderr
+
+	if err := session.Run(line); err != nil {
+		return commandError(err, stderr.String())
+	}
+	return nil
+}
+
+// commandError maps a failed remote command to a descriptive error
+func commandError(err error, stderr string) error {
+	msg := strings.TrimSpace(stderr)
+	switch {
+	case strings.Contains(msg, "No such file"):
+		return fmt.Errorf("%s: %w", msg, os.ErrNotExist)
+	case strings.Contains(msg, "Permission denied"):
+		return fmt.Errorf("%s: %w", msg, os.ErrPermission)
+	case msg != "":
+		return fmt.Errorf("%s: %w", msg, err)
+	default:
+		return err
+	}
+}
+
+// shellQuote quotes a value for safe use as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Exec runs a command on the server as a source.Runner, for apps whose
+// logs are read with docker or kubectl there. The programs must be in
+// remotePrograms.
+func (c *Client) Exec(ctx context.Context, w io.Writer, stages ...[]string) error {
+	if len(stages) == 0 || slices.ContainsFunc(stages, func(stage []string) bool { return len(stage) == 0 }) {
+		return fmt.Errorf("empty remote command")
+	}
+	cmd := command(stages[0][0], stages[0][1:]...).combineStderr()
+	for _, stage := range stages[1:] {
+		cmd = cmd.pipe(stage[0], stage[1:]...)
+	}
+	line, err := cmd.build()
+	if err != nil {
+		return err
+	}
+
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stdout = w
+	session.Stderr = &stderr
+	if err := session.Start(line); err != nil {
+		return err
+	}
+
+	// Stop the command on the server when the caller gives up
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	err = session.Wait()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	var exit *ssh.ExitError
+	if errors.As(err, &exit) {
+		return &source.ExitError{Status: exit.ExitStatus(), Stderr: stderr.String()}
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+package ssh
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestClientExecEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages [][]string
+	}{
+		{name: "no stages"},
+		{name: "empty first stage", stages: [][]string{{}}},
+		{name: "empty later stage", stages: [][]string{{"docker", "logs", "web"}, {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Refused before the connection is used, so none is needed
+			c := &Client{}
+			if err := c.Exec(context.Background(), io.Discard, tt.stages...); err == nil {
+				t.Error("Exec() accepted an empty command")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"golang.org/x/crypto/ssh"
+)
+
+// decompressors name the tools that write a compressed file to stdout,
+// for formats zgrep doesn't read
+var decompressors = map[string]string{
+	".bz2": "bzip2",
+	".xz":  "xz",
+	".zst": "zstd",
+}
+
+// Grep searches a file on the server with grep, or zgrep for gzipped
+// files, calling each for every matching and context line as it arrives.
+// Only those lines cross the network. Finding nothing is not an error.
+func (c *Client) Grep(ctx context.Context, remotePath string, opts source.GrepOptions, each func(source.GrepLine)) error {
+	grep, err := grepCommand(remotePath, opts)
+	if err != nil {
+		return err
+	}
+	cmd, err := grep.build()
+	if err != nil {
+		return err
+	}
+
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.Start(cmd); err != nil {
+		return err
+	}
+
+	// Stop the search on the server when the caller gives up
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	scanErr := source.ScanGrep(stdout, each)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if scanErr != nil {
+		return fmt.Errorf("grep %s: %w", remotePath, scanErr)
+	}
+
+	err = session.Wait()
+	var exit *ssh.ExitError
+	if errors.As(err, &exit) && exit.ExitStatus() == 1 && strings.TrimSpace(stderr.String()) == "" {
+		// grep found nothing
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("grep %s: %w", remotePath, commandError(err, stderr.String()))
+	}
+	return nil
+}
+
+// grepCommand builds the command searching a file, decompressing it on
+// the way if needed
+func grepCommand(remotePath string, opts source.GrepOptions) (remoteCommand, error) {
+	args, err := source.GrepArgs(opts)
+	if err != nil {
+		return remoteCommand{}, err
+	}
+
+	ext := compress.Ext(remotePath)
+	switch {
+	case ext == "":
+		return command("grep", append(args, "--", remotePath)...), nil
+	case ext == ".gz":
+		return command("zgrep", append(args, "--", remotePath)...), nil
+	case decompressors[ext] != "":
+		// The exit status of grep is the pipeline's
+		return command(decompressors[ext], "-dc", "--", remotePath).pipe("grep", args...), nil
+	default:
+		return remoteCommand{}, fmt.Errorf("unsupported compression: %q", ext)
+	}
+}
@@ -0,0 +1,421 @@
+package ssh
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
+)
+
+// UnknownHostError is returned when a host's key is not in any known_hosts
+// file. The caller should show the fingerprint and call TrustHost if the
+// user accepts it.
+type UnknownHostError struct {
+	Host string
+	Key  ssh.PublicKey
+}
+
+func (e *UnknownHostError) Error() string {
+	return fmt.Sprintf("host key for %s is not known (%s fingerprint %s)",
+		e.Host, e.Key.Type(), Fingerprint(e.Key))
+}
+
+// HostKeyChangedError is returned when a host presents a key that differs
+// from the one recorded in a known_hosts file
+type HostKeyChangedError struct {
+	Host string
+	Key  ssh.PublicKey
+	Want []knownhosts.KnownKey
+
+	logxFile string // The logx known_hosts file, which 'logx hosts forget' edits
+}
+
+func (e *HostKeyChangedError) Error() string {
+	var known, others []string
+	inLogx := false
+	for _, want := range e.Want {
+		known = append(known, fmt.Sprintf("%s:%d", want.Filename, want.Line))
+		if e.logxFile != "" && want.Filename == e.logxFile {
+			inLogx = true
+		} else {
+			others = append(others, fmt.Sprintf("line %d of %s", want.Line, want.Filename))
+		}
+	}
+
+	// logx can only forget the keys it recorded itself; keys in the user's
+	// OpenSSH file have to be removed there, as ssh asks
+	var fixes []string
+	if inLogx {
+		fixes = append(fixes, fmt.Sprintf("run 'logx hosts forget %s'", e.Host))
+	}
+	if len(others) > 0 {
+		fixes = append(fixes, "remove "+strings.Join(others, ", "))
+	}
+	return fmt.Sprintf("REMOTE HOST IDENTIFICATION HAS CHANGED for %s: got %s fingerprint %s, "+
+		"which does not match the key recorded in %s. Refusing to connect; if the change is expected, "+
+		"%s and try again",
+		e.Host, e.Key.Type(), Fingerprint(e.Key), strings.Join(known, ", "), strings.Join(fixes, " and "))
+}
+
+// KnownHost is one entry of a known_hosts file
+type KnownHost struct {
+	File        string
+	Line        int
+	Hosts       []string
+	KeyType     string
+	Fingerprint string
+}
+
+// Fingerprint returns the SHA256 fingerprint of a key as shown by OpenSSH
+func Fingerprint(key ssh.PublicKey) string {
+	return ssh.FingerprintSHA256(key)
+}
+
+// KnownHostsPath returns the logx-specific known_hosts file path
+func KnownHostsPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "known_hosts"), nil
+}
+
+// knownHostsFiles returns the known_hosts files that exist, the user's
+// OpenSSH file first and the logx file second
+func knownHostsFiles() ([]string, error) {
+	var files []string
+
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".ssh", "known_hosts"))
+	}
+
+	logxFile, err := KnownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, logxFile)
+
+	var existing []string
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			existing = append(existing, f)
+		}
+	}
+	return existing, nil
+}
+
+// hostKeyCallback verifies host keys against the known_hosts files. It also
+// returns the key algorithms already known for addr, so the server is asked
+// for a key type we can actually check.
+func hostKeyCallback(addr string) (ssh.HostKeyCallback, []string, error) {
+	files, err := knownHostsFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	logxFile, err := KnownHostsPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	check := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		return &UnknownHostError{Host: hostname, Key: key}
+	}
+	var algorithms []string
+
+	if len(files) > 0 {
+		known, err := knownhosts.New(files...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read known_hosts: %w", err)
+		}
+		check = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			err := known(hostname, remote, key)
+			var keyErr *knownhosts.KeyError
+			if errors.As(err, &keyErr) {
+				if len(keyErr.Want) == 0 {
+					return &UnknownHostError{Host: hostname, Key: key}
+				}
+				return &HostKeyChangedError{Host: hostname, Key: key, Want: keyErr.Want, logxFile: logxFile}
+			}
+			return err
+		}
+		algorithms = knownAlgorithms(known, addr)
+	}
+
+	return check, algorithms, nil
+}
+
+// knownAlgorithms asks the known_hosts callback which keys it has for addr
+// by presenting a throwaway key and reading the expected ones from the error
+func knownAlgorithms(known ssh.HostKeyCallback, addr string) []string {
+	probe, err := probeKey()
+	if err != nil {
+		return nil
+	}
+
+	var keyErr *knownhosts.KeyError
+	if !errors.As(known(addr, &net.TCPAddr{}, probe), &keyErr) {
+		return nil
+	}
+
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, want := range keyErr.Want {
+		for _, algo := range keyAlgorithms(want.Key.Type()) {
+			if !seen[algo] {
+				seen[algo] = true
+				algorithms = append(algorithms, algo)
+			}
+		}
+	}
+	return algorithms
+}
+
+// keyAlgorithms maps a key type to the host key algorithms that use it
+func keyAlgorithms(keyType string) []string {
+	if keyType == ssh.KeyAlgoRSA {
+		return []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	}
+	return []string{keyType}
+}
+
+// probeKey is a fixed ed25519 public key that is never a real host key
+func probeKey() (ssh.PublicKey, error) {
+	const probe = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(probe))
+	return key, err
+}
+
+// TrustHost records a host key in the logx known_hosts file
+func TrustHost(host string, key ssh.PublicKey) error {
+	path, err := KnownHostsPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(withPort(host))}, key)
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return fmt.Errorf("failed to write known_hosts: %w", err)
+	}
+	return nil
+}
+
+// ForgetHost removes every key for host from the logx known_hosts file and
+// returns how many entries were removed
+func ForgetHost(host string) (int, error) {
+	path, err := KnownHostsPath()
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	target := knownhosts.Normalize(withPort(host))
+	var kept []string
+	removed := 0
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		_, hosts, _, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err == nil && matchesHost(hosts, target) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	out := strings.Join(kept, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	return removed, os.WriteFile(path, []byte(out), 0600)
+}
+
+// ListKnownHosts returns the entries of every known_hosts file logx reads
+func ListKnownHosts() ([]KnownHost, error) {
+	files, err := knownHostsFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []KnownHost
+	for _, file := range files {
+		entries, err := readKnownHosts(file)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+	return result, nil
+}
+
+func readKnownHosts(path string) ([]KnownHost, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []KnownHost
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		marker, hosts, key, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err != nil || marker != "" {
+			continue
+		}
+		result = append(result, KnownHost{
+			File:        path,
+			Line:        lineNum,
+			Hosts:       hosts,
+			KeyType:     key.Type(),
+			Fingerprint: Fingerprint(key),
+		})
+	}
+	return result, scanner.Err()
+}
+
+// FetchHostKey reaches target the way ConnectVia does, through each jump
+// host in order, just far enough to read its host key. It returns the key
+// and the address ConnectVia checks it under. The jump hosts are logged in
+// to, so their keys must be trusted already.
+func FetchHostKey(target Endpoint, jumps []Endpoint) (string, ssh.PublicKey, error) {
+	var chain []*ssh.Client
+	defer func() {
+		for i := len(chain) - 1; i >= 0; i-- {
+			_ = chain[i].Close()
+		}
+	}()
+
+	var via *ssh.Client
+	for _, hop := range jumps {
+		conn, err := dialEndpoint(via, hop)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to connect to jump host %s: %w", withPort(hop.Host), err)
+		}
+		chain = append(chain, conn)
+		via = conn
+	}
+
+	errGotKey := errors.New("got host key")
+	var hostKey ssh.PublicKey
+	config := &ssh.ClientConfig{
+		User: "logx",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errGotKey
+		},
+		Timeout: 10 * time.Second,
+	}
+
+	addr := withPort(target.Host)
+	var err error
+	if via == nil {
+		var conn *ssh.Client
+		if conn, err = ssh.Dial("tcp", addr, config); err == nil {
+			conn.Close()
+		}
+	} else {
+		var tunnel net.Conn
+		if tunnel, err = via.Dial("tcp", addr); err == nil {
+			_, _, _, err = ssh.NewClientConn(tunnel, addr, config)
+			_ = tunnel.Close()
+		}
+	}
+	if hostKey == nil {
+		return "", nil, fmt.Errorf("failed to read host key from %s: %w", addr, err)
+	}
+	return addr, hostKey, nil
+}
+
+// matchesHost reports whether any known_hosts pattern matches the
+// normalized address, including hashed (|1|salt|hash) entries
+func matchesHost(patterns []string, target string) bool {
+	for _, pattern := range patterns {
+		if pattern == target {
+			return true
+		}
+		if !strings.HasPrefix(pattern, "|1|") {
+			continue
+		}
+		parts := strings.Split(pattern[len("|1|"):], "|")
+		if len(parts) != 2 {
+			continue
+		}
+		salt, err1 := base64.StdEncoding.DecodeString(parts[0])
+		hash, err2 := base64.StdEncoding.DecodeString(parts[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		mac := hmac.New(sha1.New, salt)
+		mac.Write([]byte(target))
+		if hmac.Equal(mac.Sum(nil), hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// withPort adds the default SSH port when host has none
+func withPort(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), "22")
+}
+
+// ConfirmHostKey shows a host's fingerprint on the terminal and asks whether
+// to trust it. It refuses when stdin is not a terminal. The prompt goes to
+// stderr, as OpenSSH's does, so that it stays visible and out of piped
+// output.
+func ConfirmHostKey(host string, key ssh.PublicKey) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("host key for %s is not known and stdin is not a terminal; "+
+			"run 'logx hosts trust %s' first", host, host)
+	}
+
+	fmt.Fprintf(os.Stderr, "The authenticity of host '%s' can't be established.\n", host)
+	fmt.Fprintf(os.Stderr, "%s key fingerprint is %s.\n", key.Type(), Fingerprint(key))
+	fmt.Fprint(os.Stderr, "Are you sure you want to continue connecting (yes/no)? ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "yes" || answer == "y", nil
+}
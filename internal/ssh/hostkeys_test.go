@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestHostKeyChangedAdvice(t *testing.T) {
+	old, current := newHostKey(t), newHostKey(t)
+	line := func(host string) string {
+		return knownhosts.Line([]string{knownhosts.Normalize(host)}, old) + "\n"
+	}
+
+	tests := []struct {
+		name     string
+		openssh  string // Content of ~/.ssh/known_hosts
+		logx     string // Content of the logx known_hosts file
+		want     []string
+		dontWant []string
+	}{
+		{
+			name:     "key recorded by logx",
+			logx:     line("db1:22"),
+			want:     []string{"run 'logx hosts forget db1:22'"},
+			dontWant: []string{"remove line"},
+		},
+		{
+			name:     "key in the OpenSSH file",
+			openssh:  "# comment\n" + line("db1:22"),
+			want:     []string{"remove line 2 of ", filepath.Join(".ssh", "known_hosts")},
+			dontWant: []string{"logx hosts forget"},
+		},
+		{
+			name:    "keys in both",
+			openssh: line("db1:22"),
+			logx:    line("db1:22"),
+			want:    []string{"run 'logx hosts forget db1:22' and remove line 1 of "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, configHome := t.TempDir(), t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", configHome)
+			if tt.openssh != "" {
+				if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(tt.openssh), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.logx != "" {
+				path, err := KnownHostsPath()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(tt.logx), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			check, _, err := hostKeyCallback("db1:22")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = check("db1:22", &net.TCPAddr{}, current)
+			var changed *HostKeyChangedError
+			if !errors.As(err, &changed) {
+				t.Fatalf("check() error = %v, want a HostKeyChangedError", err)
+			}
+			msg := err.Error()
+			for _, want := range tt.want {
+				if !strings.Contains(msg, want) {
+					t.Errorf("error %q does not contain %q", msg, want)
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(msg, dontWant) {
+					t.Errorf("error %q contains %q", msg, dontWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+)
+
+// ConnectServer establishes SSH connection to one of an app's servers,
+// going through the app's (or the server's) jump hosts
+func ConnectServer(cfg *config.Config, app *config.App, host string) (*Client, error) {
+	target, jumps, err := ResolveServer(cfg, app, host)
+	if err != nil {
+		return nil, err
+	}
+	return ConnectVia(target, jumps)
+}
+
+// ConnectServerInteractive is ConnectServer for command-line use: when a
+// host is seen for the first time the user is asked to confirm its
+// fingerprint, and the key is recorded before connecting again
+func ConnectServerInteractive(cfg *config.Config, app *config.App, host string) (*Client, error) {
+	client, err := ConnectServer(cfg, app, host)
+	err = RetryTrustingHosts(err, func() error {
+		client, err = ConnectServer(cfg, app, host)
+		return err
+	})
+	return client, err
+}
+
+// RetryTrustingHosts handles err from a connection attempt: while it is an
+// unknown host key, the user is asked to confirm the host, its key is
+// recorded and retry is called for the next error. Each retry may stop at the
+// next unknown host of a jump chain; a host already trusted ends the loop, so
+// that a server presenting a different key on every connection is not asked
+// about forever.
+func RetryTrustingHosts(err error, retry func() error) error {
+	trusted := make(map[string]bool)
+	for {
+		var unknown *UnknownHostError
+		if !errors.As(err, &unknown) || trusted[unknown.Host] {
+			return err
+		}
+
+		ok, promptErr := ConfirmHostKey(unknown.Host, unknown.Key)
+		if promptErr != nil {
+			return promptErr
+		}
+		if !ok {
+			return fmt.Errorf("host key verification failed for %s", unknown.Host)
+		}
+		if err := TrustHost(unknown.Host, unknown.Key); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Permanently added '%s' to the list of known hosts.\n", unknown.Host)
+		trusted[unknown.Host] = true
+		err = retry()
+	}
+}
+
+// ResolveServer works out the endpoint for one of an app's servers and the
+// chain of jump hosts in front of it. Hosts are resolved through
+// ~/.ssh/config; anything set in the logx config takes priority.
+func ResolveServer(cfg *config.Config, app *config.App, host string) (Endpoint, []Endpoint, error) {
+	user, err := cfg.GetUser(app.UserRef)
+	if err != nil {
+		return Endpoint{}, nil, err
+	}
+
+	target, hc, err := endpointFor(host, user, "")
+	if err != nil {
+		return Endpoint{}, nil, err
+	}
+
+	server := app.GetServer(host)
+	hops := app.JumpFor(server)
+
+	var jumps []Endpoint
+	switch {
+	case len(hops) > 0:
+		for _, hop := range hops {
+			hopUser := user
+			if hop.UserRef != "" {
+				if hopUser, err = cfg.GetUser(hop.UserRef); err != nil {
+					return Endpoint{}, nil, fmt.Errorf("jump host %s: %w", hop.Host, err)
+				}
+			}
+			jump, _, err := endpointFor(hop.Host, hopUser, "")
+			if err != nil {
+				return Endpoint{}, nil, err
+			}
+			jumps = append(jumps, jump)
+		}
+
+	case strings.TrimSpace(server.Jump) != config.NoJump:
+		if jumps, err = proxyJumpChain(hc.ProxyJump, user, 0); err != nil {
+			return Endpoint{}, nil, err
+		}
+	}
+
+	return target, jumps, nil
+}
+
+// ResolveHost works out the endpoint for a host outside any app, and the
+// jump hosts in front of it, from ~/.ssh/config alone
+func ResolveHost(host string) (Endpoint, []Endpoint, error) {
+	user := &config.User{}
+	target, hc, err := endpointFor(host, user, "")
+	if err != nil {
+		return Endpoint{}, nil, err
+	}
+	jumps, err := proxyJumpChain(hc.ProxyJump, user, 0)
+	if err != nil {
+		return Endpoint{}, nil, err
+	}
+	return target, jumps, nil
+}
+
+// endpointFor builds the endpoint for a host, filling in whatever the logx
+// user leaves open from ~/.ssh/config. sshUser is a login name taken from a
+// ProxyJump spec, which outranks both.
+func endpointFor(host string, user *config.User, sshUser string) (Endpoint, HostConfig, error) {
+	alias, port := splitHostPort(host)
+
+	username := sshUser
+	if username == "" {
+		username = user.Username
+	}
+
+	hc, err := LookupHostConfig(alias, username)
+	if err != nil {
+		return Endpoint{}, HostConfig{}, fmt.Errorf("failed to read ssh config: %w", err)
+	}
+
+	if username == "" {
+		username = hc.User
+	}
+	if port == "" {
+		port = hc.Port
+	}
+	addr := hc.HostName
+	if port != "" {
+		addr = net.JoinHostPort(addr, port)
+	}
+
+	auth, err := AuthForUser(user)
+	if err != nil {
+		return Endpoint{}, HostConfig{}, err
+	}
+	if len(user.KeyFiles) == 0 && len(hc.IdentityFiles) > 0 {
+		auth.KeyFiles = hc.IdentityFiles
+	}
+
+	return Endpoint{Host: addr, Username: username, Auth: auth}, hc, nil
+}
+
+// proxyJumpChain turns an OpenSSH ProxyJump value ([user@]host[:port],...)
+// into endpoints. A first hop with its own ProxyJump is reached through it.
+func proxyJumpChain(spec string, user *config.User, depth int) ([]Endpoint, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.EqualFold(spec, "none") {
+		return nil, nil
+	}
+	if depth >= maxIncludeDepth {
+		return nil, fmt.Errorf("ProxyJump chain for %s is too deep", spec)
+	}
+
+	var chain []Endpoint
+	for i, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		sshUser := ""
+		if at := strings.LastIndex(part, "@"); at >= 0 {
+			sshUser = part[:at]
+			part = part[at+1:]
+		}
+
+		jump, hc, err := endpointFor(part, user, sshUser)
+		if err != nil {
+			return nil, err
+		}
+		if sshUser == "" && hc.User != "" {
+			jump.Username = hc.User
+		}
+
+		if i == 0 {
+			before, err := proxyJumpChain(hc.ProxyJump, user, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, before...)
+		}
+		chain = append(chain, jump)
+	}
+	return chain, nil
+}
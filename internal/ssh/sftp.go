@@ -0,0 +1,214 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/pkg/sftp"
+)
+
+// sftpClient returns the SFTP session for this connection, opening it on
+// first use. It returns an error if the server has the subsystem disabled,
+// in which case callers fall back to running shell commands.
+func (c *Client) sftpClient() (*sftp.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sftp != nil || c.sftpErr != nil {
+		return c.sftp, c.sftpErr
+	}
+
+	client, err := sftp.NewClient(c.conn)
+	if err != nil {
+		c.sftpErr = fmt.Errorf("sftp unavailable: %w", err)
+		return nil, c.sftpErr
+	}
+	c.sftp = client
+	return client, nil
+}
+
+// Stat returns information about a remote file, following symlinks
+func (c *Client) Stat(remotePath string) (source.FileInfo, error) {
+	if client, err := c.sftpClient(); err == nil {
+		info, err := client.Stat(remotePath)
+		if err != nil {
+			return source.FileInfo{}, fmt.Errorf("stat %s: %w", remotePath, err)
+		}
+		return fileInfo(remotePath, info), nil
+	}
+
+	output, err := c.run(command("stat", "-L", "-c", "%s %Y %f", "--", remotePath))
+	if err != nil {
+		return source.FileInfo{}, fmt.Errorf("stat %s: %w", remotePath, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 3 {
+		return source.FileInfo{}, fmt.Errorf("stat %s: unexpected output %q", remotePath, output)
+	}
+	size, err1 := strconv.ParseInt(fields[0], 10, 64)
+	mtime, err2 := strconv.ParseInt(fields[1], 10, 64)
+	rawMode, err3 := strconv.ParseUint(fields[2], 16, 32)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return source.FileInfo{}, fmt.Errorf("stat %s: %w", remotePath, err)
+	}
+
+	return source.FileInfo{
+		Name:    path.Base(remotePath),
+		Path:    remotePath,
+		Size:    size,
+		ModTime: time.Unix(mtime, 0),
+		Mode:    unixMode(uint32(rawMode)),
+	}, nil
+}
+
+// ReadDir lists the entries of a remote directory
+func (c *Client) ReadDir(dir string) ([]source.FileInfo, error) {
+	if client, err := c.sftpClient(); err == nil {
+		entries, err := client.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("read dir %s: %w", dir, err)
+		}
+		result := make([]source.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			result = append(result, fileInfo(path.Join(dir, entry.Name()), entry))
+		}
+		return result, nil
+	}
+
+	output, err := c.run(command("find", "-L", dir, "-mindepth", "1", "-maxdepth", "1",
+		"-printf", `%s\t%T@\t%m\t%y\t%f\n`))
+	if err != nil {
+		return nil, fmt.Errorf("read dir %s: %w", dir, err)
+	}
+
+	var result []source.FileInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[0], 10, 64)
+		mtime, _ := strconv.ParseFloat(fields[1], 64)
+		perm, _ := strconv.ParseUint(fields[2], 8, 32)
+
+		mode := os.FileMode(perm) & os.ModePerm
+		if fields[3] == "d" {
+			mode |= os.ModeDir
+		}
+
+		result = append(result, source.FileInfo{
+			Name:    fields[4],
+			Path:    path.Join(dir, fields[4]),
+			Size:    size,
+			ModTime: time.Unix(int64(mtime), 0),
+			Mode:    mode,
+		})
+	}
+	return result, nil
+}
+
+// ReadRange copies length bytes of a remote file starting at offset to w.
+// A negative length reads to the end of the file. It returns the number of
+// bytes copied, which is less than length if the file is shorter.
+func (c *Client) ReadRange(remotePath string, offset, length int64, w io.Writer) (int64, error) {
+	if client, err := c.sftpClient(); err == nil {
+		f, err := client.Open(remotePath)
+		if err != nil {
+			return 0, fmt.Errorf("open %s: %w", remotePath, err)
+		}
+		defer f.Close()
+
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("seek %s: %w", remotePath, err)
+		}
+		if length < 0 {
+			return f.WriteTo(w)
+		}
+		n, err := io.CopyN(w, f, length)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		return n, err
+	}
+
+	return c.readRangeExec(remotePath, offset, length, w)
+}
+
+// readRangeExec is ReadRange for servers without SFTP. The session is closed
+// as soon as length bytes have arrived, so only the requested range is sent.
+func (c *Client) readRangeExec(remotePath string, offset, length int64, w io.Writer) (int64, error) {
+	cmd, err := command("tail", "-c", fmt.Sprintf("+%d", offset+1), "--", remotePath).build()
+	if err != nil {
+		return 0, err
+	}
+
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := session.Start(cmd); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	if length < 0 {
+		n, err = io.Copy(w, stdout)
+	} else {
+		n, err = io.CopyN(w, stdout, length)
+		if err == nil {
+			// Got everything we asked for; don't wait for the rest
+			return n, nil
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return n, err
+	}
+
+	if err := session.Wait(); err != nil {
+		return n, fmt.Errorf("read %s: %w", remotePath, commandError(err, stderr.String()))
+	}
+	return n, nil
+}
+
+func fileInfo(remotePath string, info os.FileInfo) source.FileInfo {
+	return source.FileInfo{
+		Name:    info.Name(),
+		Path:    remotePath,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Mode:    info.Mode(),
+	}
+}
+
+// unixMode converts a raw st_mode value into an os.FileMode
+func unixMode(raw uint32) os.FileMode {
+	mode := os.FileMode(raw & 0777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= os.ModeDir
+	case 0120000:
+		mode |= os.ModeSymlink
+	}
+	return mode
+}
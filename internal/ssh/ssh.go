@@ -1,149 +1,184 @@
 package ssh
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
 // Client wraps SSH connection
 type Client struct {
-	conn *ssh.Client
+	conn  *ssh.Client
+	host  string        // Address of the target server
+	via   string        // Addresses of the jump hosts, comma separated
+	jumps []*ssh.Client // Jump host connections the session is tunnelled through
+
+	mu      sync.Mutex
+	sftp    *sftp.Client // Opened on first use
+	sftpErr error        // Set when the server has no SFTP subsystem
 }
 
-// Connect establishes SSH connection
-func Connect(host, username, password string) (*Client, error) {
-	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // For production, use proper host key verification
-		Timeout:         10 * time.Second,
-	}
-
-	// Add default port if not specified
-	if !strings.Contains(host, ":") {
-		host = host + ":22"
-	}
-
-	conn, err := ssh.Dial("tcp", host, config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
-	}
+// Endpoint describes an SSH server and how to log in to it
+type Endpoint struct {
+	Host     string
+	Username string
+	Auth     Auth
+}
 
-	return &Client{conn: conn}, nil
+// Connect establishes SSH connection using password authentication
+func Connect(host, username, password string) (*Client, error) {
+	return ConnectWithAuth(host, username, Auth{
+		Methods:  []string{AuthPassword},
+		Password: password,
+	})
 }
 
-// Close closes the SSH connection
-func (c *Client) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
-	}
-	return nil
+// ConnectWithAuth establishes SSH connection trying each auth method in order
+func ConnectWithAuth(host, username string, auth Auth) (*Client, error) {
+	return ConnectVia(Endpoint{Host: host, Username: username, Auth: auth}, nil)
 }
 
-// FileExists checks if a file exists on the remote server
-func (c *Client) FileExists(path string) (bool, error) {
-	session, err := c.conn.NewSession()
-	if err != nil {
-		return false, err
+// ConnectVia establishes SSH connection to target, tunnelling through each
+// jump host in order the way OpenSSH's ProxyJump does
+func ConnectVia(target Endpoint, jumps []Endpoint) (*Client, error) {
+	var chain []*ssh.Client
+	closeChain := func() {
+		for i := len(chain) - 1; i >= 0; i-- {
+			_ = chain[i].Close()
+		}
 	}
-	defer func(session *ssh.Session) {
-		err := session.Close()
+
+	var via *ssh.Client
+	for _, hop := range jumps {
+		conn, err := dialEndpoint(via, hop)
 		if err != nil {
-			_ = fmt.Errorf("an error occurred while closing the session %w", err)
+			closeChain()
+			return nil, fmt.Errorf("failed to connect to jump host %s: %w", withPort(hop.Host), err)
 		}
-	}(session)
+		chain = append(chain, conn)
+		via = conn
+	}
 
-	cmd := fmt.Sprintf("test -f %s && echo exists", path)
-	output, err := session.CombinedOutput(cmd)
+	conn, err := dialEndpoint(via, target)
 	if err != nil {
-		return false, nil
+		closeChain()
+		return nil, fmt.Errorf("failed to connect to %s: %w", withPort(target.Host), err)
 	}
 
-	return strings.TrimSpace(string(output)) == "exists", nil
+	hops := make([]string, len(jumps))
+	for i, hop := range jumps {
+		hops[i] = withPort(hop.Host)
+	}
+	return &Client{conn: conn, host: withPort(target.Host), via: strings.Join(hops, ","), jumps: chain}, nil
 }
 
-// DownloadFile downloads a file from remote server to local temp directory
-func (c *Client) DownloadFile(remotePath string) (string, error) {
-	session, err := c.conn.NewSession()
+// dialEndpoint opens an SSH connection to e, over TCP when via is nil or
+// through a direct-tcpip channel of the via connection otherwise
+func dialEndpoint(via *ssh.Client, e Endpoint) (*ssh.Client, error) {
+	methods, cleanup, err := e.Auth.authMethods()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer func(session *ssh.Session) {
-		err := session.Close()
-		if err != nil {
-			_ = fmt.Errorf("an error occurred while closing the session %w", err)
-		}
-	}(session)
+	defer cleanup()
 
-	// Create temp file
-	tmpFile, err := os.CreateTemp("", "logx-*.log")
-	if err != nil {
-		return "", err
-	}
-	defer func(tmpFile *os.File) {
-		err := tmpFile.Close()
-		if err != nil {
-			_ = fmt.Errorf("an error occurred while closing the temp file %w", err)
-		}
-	}(tmpFile)
+	// Add default port if not specified
+	addr := withPort(e.Host)
 
-	// Use cat to read the file
-	cmd := fmt.Sprintf("cat %s", remotePath)
-	output, err := session.StdoutPipe()
+	hostKeyCallback, hostKeyAlgorithms, err := hostKeyCallback(addr)
 	if err != nil {
-		return "", err
-	}
-
-	if err := session.Start(cmd); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Copy to temp file
-	if _, err := io.Copy(tmpFile, output); err != nil {
-		return "", err
+	config := &ssh.ClientConfig{
+		User:              e.Username,
+		Auth:              methods,
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: hostKeyAlgorithms,
+		Timeout:           10 * time.Second,
 	}
 
-	if err := session.Wait(); err != nil {
-		return "", fmt.Errorf("failed to download file: %w", err)
+	if via == nil {
+		return ssh.Dial("tcp", addr, config)
 	}
 
-	return tmpFile.Name(), nil
-}
-
-// ListFiles lists files matching a pattern in a directory
-func (c *Client) ListFiles(dir, pattern string) ([]string, error) {
-	session, err := c.conn.NewSession()
+	tunnel, err := via.Dial("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
-	defer func(session *ssh.Session) {
-		err := session.Close()
-		if err != nil {
-			_ = fmt.Errorf("an error occurred while closing the session %w", err)
-		}
-	}(session)
-
-	cmd := fmt.Sprintf("ls -1 %s 2>/dev/null | grep '%s' || true", dir, pattern)
-	output, err := session.CombinedOutput(cmd)
+	conn, chans, reqs, err := ssh.NewClientConn(tunnel, addr, config)
 	if err != nil {
+		_ = tunnel.Close()
 		return nil, err
 	}
+	return ssh.NewClient(conn, chans, reqs), nil
+}
 
-	files := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var result []string
-	for _, f := range files {
-		if f != "" {
-			result = append(result, filepath.Join(dir, f))
+// Close closes the SSH connection and any jump host connections
+func (c *Client) Close() error {
+	var err error
+	if c.sftp != nil {
+		_ = c.sftp.Close()
+	}
+	if c.conn != nil {
+		err = c.conn.Close()
+	}
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		_ = c.jumps[i].Close()
+	}
+	return err
+}
+
+// KeepAlive probes the connection every interval until ctx is cancelled.
+// When the server stops answering the connection is closed, so that any
+// operation blocked on it fails instead of hanging, and the error returned.
+func (c *Client) KeepAlive(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := c.conn.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err = <-reply:
+		case <-time.After(interval):
+			err = fmt.Errorf("no keepalive reply from %s", c.host)
+		}
+		if err != nil {
+			_ = c.Close()
+			return err
 		}
 	}
+}
+
+// Follow streams complete lines appended to a remote file after offset,
+// the way tail -F does. It returns when ctx is cancelled or the connection
+// fails.
+func (c *Client) Follow(ctx context.Context, remotePath string, offset int64, inode uint64, events chan<- source.FollowEvent) error {
+	return source.Tail(ctx, c, remotePath, offset, inode, c.Inode, events)
+}
 
-	return result, nil
+// Fetch downloads a file from the server to the local download cache,
+// fetching only what changed since the last download
+func (c *Client) Fetch(remotePath string) (string, error) {
+	localPath, _, err := c.SyncFile(remotePath)
+	return localPath, err
 }
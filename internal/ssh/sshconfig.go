@@ -0,0 +1,394 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HostConfig holds the OpenSSH client settings that apply to one host
+type HostConfig struct {
+	HostName      string
+	Port          string
+	User          string
+	IdentityFiles []string
+	ProxyJump     string
+}
+
+// maxIncludeDepth stops runaway Include recursion, matching OpenSSH
+const maxIncludeDepth = 16
+
+// sshConfigFiles returns the OpenSSH client config files in the order they
+// are read: the user's file first, then the system-wide one. Relative
+// Include paths resolve against the directory of the file they start from.
+var sshConfigFiles = func() []string {
+	var files []string
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".ssh", "config"))
+	}
+	return append(files, "/etc/ssh/ssh_config")
+}
+
+// LookupHostConfig evaluates the OpenSSH client config for a host alias.
+// remoteUser is the user logx will log in as; it is used for Match user.
+func LookupHostConfig(alias, remoteUser string) (HostConfig, error) {
+	eval := &configEval{alias: alias, remoteUser: remoteUser}
+	for _, file := range sshConfigFiles() {
+		if err := eval.readFile(file, filepath.Dir(file), 0); err != nil {
+			return HostConfig{}, err
+		}
+	}
+
+	hc := eval.result
+	hc.HostName = eval.hostName()
+	for i, identity := range hc.IdentityFiles {
+		hc.IdentityFiles[i] = eval.expandTokens(identity, hc.HostName)
+	}
+	return hc, nil
+}
+
+// configEval applies config lines for one host, first value wins
+type configEval struct {
+	alias      string
+	remoteUser string
+	result     HostConfig
+	seen       map[string]bool
+}
+
+// readFile applies a config file; baseDir is where relative Include paths
+// resolve, ~/.ssh for the user's config and /etc/ssh for the system one
+func (e *configEval) readFile(file, baseDir string, depth int) error {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Blocks only last until the end of the file they start in
+	active := true
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		keyword, args := splitConfigLine(scanner.Text())
+		if keyword == "" {
+			continue
+		}
+
+		switch keyword {
+		case "host":
+			active = e.matchHost(args)
+		case "match":
+			matched, err := e.matchCriteria(args)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", file, lineNum, err)
+			}
+			active = matched
+		case "include":
+			if !active {
+				continue
+			}
+			if depth >= maxIncludeDepth {
+				return fmt.Errorf("%s:%d: too many nested includes", file, lineNum)
+			}
+			for _, pattern := range args {
+				if err := e.include(pattern, baseDir, depth+1); err != nil {
+					return err
+				}
+			}
+		default:
+			if active {
+				e.apply(keyword, args)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+func (e *configEval) include(pattern, baseDir string, depth int) error {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(baseDir, pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := e.readFile(match, baseDir, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *configEval) apply(keyword string, args []string) {
+	if len(args) == 0 {
+		return
+	}
+	if e.seen == nil {
+		e.seen = make(map[string]bool)
+	}
+
+	// IdentityFile accumulates, every other keyword keeps its first value
+	if keyword == "identityfile" {
+		e.result.IdentityFiles = append(e.result.IdentityFiles, args[0])
+		return
+	}
+	if e.seen[keyword] {
+		return
+	}
+
+	switch keyword {
+	case "hostname":
+		e.result.HostName = args[0]
+	case "port":
+		e.result.Port = args[0]
+	case "user":
+		e.result.User = args[0]
+	case "proxyjump":
+		e.result.ProxyJump = args[0]
+	default:
+		return
+	}
+	e.seen[keyword] = true
+}
+
+// matchHost evaluates a Host line: at least one pattern must match and no
+// negated pattern may match
+func (e *configEval) matchHost(patterns []string) bool {
+	return matchPatternList(patterns, e.alias)
+}
+
+// matchCriteria evaluates a Match line. Criteria logx cannot evaluate, such
+// as exec or tagged, never match, so their settings are skipped rather than
+// misapplied.
+func (e *configEval) matchCriteria(args []string) (bool, error) {
+	matched := true
+	for i := 0; i < len(args); i++ {
+		criterion := strings.ToLower(args[i])
+		negate := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+
+		var result bool
+		switch criterion {
+		case "all":
+			result = true
+		case "canonical":
+			result = false
+		case "final":
+			result = true
+		default:
+			if i+1 >= len(args) {
+				return false, fmt.Errorf("match %s requires an argument", criterion)
+			}
+			i++
+			patterns := strings.Split(args[i], ",")
+			switch criterion {
+			case "host":
+				result = matchPatternList(patterns, e.hostName())
+			case "originalhost":
+				result = matchPatternList(patterns, e.alias)
+			case "user":
+				result = matchPatternList(patterns, e.user())
+			case "localuser":
+				result = matchPatternList(patterns, localUsername())
+			default:
+				result = false
+			}
+		}
+
+		if negate {
+			result = !result
+		}
+		matched = matched && result
+	}
+	return matched, nil
+}
+
+// hostName is the target host after any HostName seen so far
+func (e *configEval) hostName() string {
+	if e.result.HostName != "" {
+		return e.expandTokens(e.result.HostName, e.alias)
+	}
+	return e.alias
+}
+
+func (e *configEval) user() string {
+	if e.remoteUser != "" {
+		return e.remoteUser
+	}
+	if e.result.User != "" {
+		return e.result.User
+	}
+	return localUsername()
+}
+
+// expandTokens handles the ~ prefix and the % tokens OpenSSH accepts in
+// HostName and IdentityFile; host is what %h stands for
+func (e *configEval) expandTokens(value, host string) string {
+	value = expandHome(value)
+	if !strings.Contains(value, "%") {
+		return value
+	}
+
+	home, _ := os.UserHomeDir()
+	localHost, _ := os.Hostname()
+	port := e.result.Port
+	if port == "" {
+		port = "22"
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'd':
+			b.WriteString(home)
+		case 'u':
+			b.WriteString(localUsername())
+		case 'l':
+			b.WriteString(localHost)
+		case 'h':
+			b.WriteString(host)
+		case 'n':
+			b.WriteString(e.alias)
+		case 'p':
+			b.WriteString(port)
+		case 'r':
+			b.WriteString(e.user())
+		default:
+			b.WriteByte('%')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// splitConfigLine splits a config line into a lower-cased keyword and its
+// arguments, handling "Keyword=value", quotes and comments
+func splitConfigLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil
+	}
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+
+	var args []string
+	var current strings.Builder
+	inQuotes, hasToken := false, false
+	for _, r := range rest {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasToken {
+				args = append(args, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		case r == '#' && !inQuotes && !hasToken:
+			return keyword, args
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if hasToken {
+		args = append(args, current.String())
+	}
+	return keyword, args
+}
+
+// matchPatternList reports whether value matches a list of OpenSSH
+// patterns, where a matching negated pattern always wins
+func matchPatternList(patterns []string, value string) bool {
+	value = strings.ToLower(value)
+	matched := false
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if strings.HasPrefix(pattern, "!") {
+			if matchPattern(pattern[1:], value) {
+				return false
+			}
+			continue
+		}
+		if matchPattern(pattern, value) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchPattern matches value against an OpenSSH pattern, where * stands
+// for any run of characters, ? for exactly one and everything else,
+// including [ and \, for itself
+func matchPattern(pattern, value string) bool {
+	// On a mismatch, let the last * swallow one more character and retry
+	p, v := 0, 0
+	star, starV := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, starV = p, v
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case star >= 0:
+			starV++
+			p, v = star+1, starV
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+func localUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// splitHostPort separates an optional port from a host, accepting bare
+// hosts and bracketed IPv6 addresses
+func splitHostPort(hostport string) (string, string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return strings.Trim(hostport, "[]"), ""
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return hostport, ""
+	}
+	return host, port
+}
@@ -0,0 +1,252 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useConfigFiles makes LookupHostConfig read the given files, named
+// relative to dir, instead of the user's and the system's
+func useConfigFiles(t *testing.T, dir string, files map[string]string, order ...string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := sshConfigFiles
+	t.Cleanup(func() { sshConfigFiles = saved })
+	sshConfigFiles = func() []string {
+		var paths []string
+		for _, name := range order {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		return paths
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"web1", "web1", true},
+		{"web1", "web10", false},
+		{"*", "anything", true},
+		{"*", "", true},
+		{"web*", "web", true},
+		{"web*", "web-01.example.com", true},
+		{"*.example.com", "db.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"web?", "web1", true},
+		{"web?", "web", false},
+		{"web?", "web12", false},
+		{"*a*b", "xxaxxab", true},
+		{"*a*b", "xxaxxa", false},
+		{"10.0.0.*", "10.0.0.7", true},
+		{"10.0.0.*", "10.0.1.7", false},
+		// Only * and ? are special; path.Match would treat these as syntax
+		{"web[12]", "web1", false},
+		{"web[12]", "web[12]", true},
+		{`web\*`, `web\1`, true},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupHostConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		alias  string
+		user   string
+		want   HostConfig
+	}{
+		{
+			name: "first value wins",
+			config: `Host web
+  HostName web.internal
+  Port 2200
+Host *
+  HostName other.internal
+  Port 22
+  User deploy
+`,
+			alias: "web",
+			want:  HostConfig{HostName: "web.internal", Port: "2200", User: "deploy"},
+		},
+		{
+			name: "identity files accumulate",
+			config: `Host web
+  IdentityFile /keys/web
+Host *
+  IdentityFile /keys/default
+`,
+			alias: "web",
+			want:  HostConfig{HostName: "web", IdentityFiles: []string{"/keys/web", "/keys/default"}},
+		},
+		{
+			name: "negated host pattern excludes",
+			config: `Host *.example.com !bastion.example.com
+  ProxyJump bastion.example.com
+`,
+			alias: "bastion.example.com",
+			want:  HostConfig{HostName: "bastion.example.com"},
+		},
+		{
+			name: "negated host pattern lets others through",
+			config: `Host *.example.com !bastion.example.com
+  ProxyJump bastion.example.com
+`,
+			alias: "web.example.com",
+			want:  HostConfig{HostName: "web.example.com", ProxyJump: "bastion.example.com"},
+		},
+		{
+			name: "negation alone never matches",
+			config: `Host !bastion
+  Port 2200
+`,
+			alias: "web",
+			want:  HostConfig{HostName: "web"},
+		},
+		{
+			name: "match host sees the hostname",
+			config: `Host web
+  HostName 10.0.0.7
+Match host 10.0.0.*
+  User ops
+`,
+			alias: "web",
+			want:  HostConfig{HostName: "10.0.0.7", User: "ops"},
+		},
+		{
+			name: "negated match criterion",
+			config: `Match !host web
+  Port 2200
+Match !originalhost web
+  User ops
+`,
+			alias: "db",
+			want:  HostConfig{HostName: "db", Port: "2200", User: "ops"},
+		},
+		{
+			name: "negated pattern in a match list",
+			config: `Match originalhost web*,!web-test
+  Port 2200
+`,
+			alias: "web-test",
+			want:  HostConfig{HostName: "web-test"},
+		},
+		{
+			name: "match user",
+			config: `Match user deploy
+  Port 2200
+Match user !deploy
+  Port 22
+`,
+			alias: "web",
+			user:  "deploy",
+			want:  HostConfig{HostName: "web", Port: "2200"},
+		},
+		{
+			name: "unsupported criterion never matches",
+			config: `Match exec "true"
+  Port 2200
+`,
+			alias: "web",
+			want:  HostConfig{HostName: "web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfigFiles(t, t.TempDir(), map[string]string{"config": tt.config}, "config")
+			got, err := LookupHostConfig(tt.alias, tt.user)
+			if err != nil {
+				t.Fatalf("LookupHostConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupHostConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupHostConfigInclude(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  HostConfig
+	}{
+		{
+			name: "relative to the user's directory",
+			files: map[string]string{
+				"user/config":        "Include conf.d/*.conf\n",
+				"user/conf.d/a.conf": "Port 2200\n",
+				"user/conf.d/b.conf": "Port 2300\nUser deploy\n",
+				"system/ssh_config":  "",
+			},
+			want: HostConfig{HostName: "web", Port: "2200", User: "deploy"},
+		},
+		{
+			name: "relative to the system directory",
+			files: map[string]string{
+				"user/config":           "",
+				"system/ssh_config":     "Include ssh_config.d/*\n",
+				"system/ssh_config.d/x": "User ops\n",
+				"user/ssh_config.d/x":   "User wrong\n",
+			},
+			want: HostConfig{HostName: "web", User: "ops"},
+		},
+		{
+			name: "nested includes keep the base directory",
+			files: map[string]string{
+				"user/config":       "Include conf.d/web\n",
+				"user/conf.d/web":   "Include keys\n",
+				"user/keys":         "IdentityFile /keys/web\n",
+				"user/conf.d/keys":  "IdentityFile /keys/wrong\n",
+				"system/ssh_config": "",
+			},
+			want: HostConfig{HostName: "web", IdentityFiles: []string{"/keys/web"}},
+		},
+		{
+			name: "inside an inactive block",
+			files: map[string]string{
+				"user/config":       "Host db\n  Include extra\n",
+				"user/extra":        "Port 2200\n",
+				"system/ssh_config": "",
+			},
+			want: HostConfig{HostName: "web"},
+		},
+		{
+			name: "user file before the system one",
+			files: map[string]string{
+				"user/config":       "Include extra\n",
+				"user/extra":        "Port 2200\n",
+				"system/ssh_config": "Port 22\nUser ops\n",
+			},
+			want: HostConfig{HostName: "web", Port: "2200", User: "ops"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfigFiles(t, t.TempDir(), tt.files, "user/config", "system/ssh_config")
+			got, err := LookupHostConfig("web", "")
+			if err != nil {
+				t.Fatalf("LookupHostConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupHostConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
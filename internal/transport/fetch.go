@@ -0,0 +1,207 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+)
+
+// Defaults for FetchOptions
+const (
+	DefaultFetchWorkers = 8
+	DefaultFetchTimeout = 2 * time.Minute
+)
+
+// FetchOptions controls how FetchAll spreads work over servers
+type FetchOptions struct {
+	Workers  int               // Servers fetched at once; DefaultFetchWorkers if zero
+	Timeout  time.Duration     // Limit for connecting to and downloading from one server; DefaultFetchTimeout if zero
+	KeepOpen bool              // Leave successful sources open in FetchResult.Source
+	Progress func(FetchResult) // Called as each server finishes, one call at a time
+
+	// Locate finds the files to fetch on each server. When nil,
+	// remotePath or a compressed copy of it is fetched.
+	Locate source.Locator
+}
+
+// FetchOptionsFor returns FetchOptions using the limits from the config
+func FetchOptionsFor(cfg *config.Config) (FetchOptions, error) {
+	workers, timeout, err := cfg.FetchLimits()
+	if err != nil {
+		return FetchOptions{}, err
+	}
+	return FetchOptions{Workers: workers, Timeout: timeout}, nil
+}
+
+// FetchResult is the outcome of fetching a file from one server
+type FetchResult struct {
+	Host       string
+	RemotePath string        // Where the file was found, which may have a compression suffix added; the newest one when several were fetched
+	LocalPath  string        // Cached copy of RemotePath; set when Err is nil
+	Inode      uint64        // Inode of RemotePath when it was fetched, or 0 if unknown
+	Files      []FetchedFile // Every file fetched, oldest first; set when Err is nil
+	Source     source.Source // Left open when KeepOpen was set and Err is nil
+	Duration   time.Duration
+	Err        error
+}
+
+// FetchedFile is one remote file and its cached copy
+type FetchedFile struct {
+	RemotePath string
+	LocalPath  string
+	Inode      uint64 // Inode of RemotePath when it was fetched, or 0 if unknown
+}
+
+// syncer is a source that reports the inode of each file it fetches, so
+// that following the file notices if it was replaced since
+type syncer interface {
+	SyncFile(path string) (string, uint64, error)
+}
+
+// FetchAll downloads remotePath from each of an app's hosts, several at a
+// time, over the app's transport. A server that fails or runs out of time
+// doesn't stop the others: every host gets a FetchResult, in the same
+// order as hosts.
+func FetchAll(ctx context.Context, cfg *config.Config, app *config.App, hosts []string, remotePath string, opts FetchOptions) []FetchResult {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultFetchWorkers
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultFetchTimeout
+	}
+
+	results := make([]FetchResult, len(hosts))
+	finished := make(chan int)
+	slots := make(chan struct{}, opts.Workers)
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				results[i] = fetchOne(ctx, cfg, app, host, remotePath, opts)
+			case <-ctx.Done():
+				results[i] = FetchResult{Host: host, RemotePath: remotePath, Err: ctx.Err()}
+			}
+			finished <- i
+		}(i, host)
+	}
+
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	for i := range finished {
+		if opts.Progress != nil {
+			opts.Progress(results[i])
+		}
+	}
+
+	return results
+}
+
+// fetchOne opens one server and syncs remotePath within opts.Timeout. When
+// time runs out the source is closed, which aborts the transfer.
+func fetchOne(ctx context.Context, cfg *config.Config, app *config.App, host, remotePath string, opts FetchOptions) FetchResult {
+	result := FetchResult{Host: host, RemotePath: remotePath}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		src       source.Source
+		abandoned bool
+	)
+
+	type outcome struct {
+		files []FetchedFile
+		err   error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		opened, err := Open(cfg, app, host)
+		if err != nil {
+			done <- outcome{err: err}
+			return
+		}
+
+		mu.Lock()
+		if abandoned {
+			mu.Unlock()
+			_ = opened.Close()
+			return
+		}
+		src = opened
+		mu.Unlock()
+
+		// Older logs may have been compressed since they were written
+		found, err := source.Locate(opened, host, remotePath, opts.Locate)
+		if err != nil {
+			done <- outcome{err: err}
+			return
+		}
+
+		var files []FetchedFile
+		for _, p := range found {
+			file := FetchedFile{RemotePath: p}
+			if s, ok := opened.(syncer); ok {
+				file.LocalPath, file.Inode, err = s.SyncFile(p)
+			} else {
+				file.LocalPath, err = opened.Fetch(p)
+			}
+			if err != nil {
+				done <- outcome{err: err}
+				return
+			}
+			files = append(files, file)
+		}
+		done <- outcome{files: files}
+	}()
+
+	select {
+	case o := <-done:
+		result.Err = o.err
+		if n := len(o.files); n > 0 {
+			result.Files = o.files
+			last := o.files[n-1]
+			result.RemotePath, result.LocalPath, result.Inode = last.RemotePath, last.LocalPath, last.Inode
+		}
+
+	case <-ctx.Done():
+		mu.Lock()
+		abandoned = true
+		mu.Unlock()
+
+		result.Err = ctx.Err()
+		if errors.Is(result.Err, context.DeadlineExceeded) {
+			result.Err = fmt.Errorf("timed out after %s", opts.Timeout)
+		}
+	}
+
+	result.Duration = time.Since(start)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if src != nil {
+		if result.Err == nil && opts.KeepOpen {
+			result.Source = src
+		} else {
+			_ = src.Close()
+		}
+	}
+
+	return result
+}
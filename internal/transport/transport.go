@@ -0,0 +1,102 @@
+// Package transport opens an app's servers over the transport the app is
+// configured with, and fetches logs from many of them at once
+package transport
+
+import (
+	"fmt"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/ssh"
+)
+
+// Open opens one of an app's servers with the app's transport: the local
+// filesystem, an SSH connection through the app's jump hosts, or for
+// docker and kubectl apps the container or pod named by host
+func Open(cfg *config.Config, app *config.App, host string) (source.Source, error) {
+	return open(cfg, app, host, ssh.ConnectServer)
+}
+
+// OpenInteractive is Open for command-line use, asking the user to confirm
+// the keys of unknown hosts as ssh.ConnectServerInteractive does
+func OpenInteractive(cfg *config.Config, app *config.App, host string) (source.Source, error) {
+	return open(cfg, app, host, ssh.ConnectServerInteractive)
+}
+
+// connectFunc connects to a server over SSH, as ssh.ConnectServer does
+type connectFunc func(cfg *config.Config, app *config.App, host string) (*ssh.Client, error)
+
+func open(cfg *config.Config, app *config.App, host string, connect connectFunc) (source.Source, error) {
+	if err := app.CheckTransport(); err != nil {
+		return nil, err
+	}
+	switch {
+	case app.Local():
+		return source.NewLocal(), nil
+
+	case app.Containers():
+		run, err := openRunner(cfg, app, app.CLIHostFor(host), connect)
+		if err != nil {
+			return nil, err
+		}
+		container, err := source.NewContainer(run, app, host)
+		if err != nil {
+			_ = run.Close()
+			return nil, err
+		}
+		return container, nil
+	}
+
+	client, err := connect(cfg, app, host)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// openRunner returns where a docker or kubectl app runs its CLI: this
+// machine when cliHost is empty, or that server over SSH
+func openRunner(cfg *config.Config, app *config.App, cliHost string, connect connectFunc) (source.Runner, error) {
+	if cliHost == "" {
+		return source.NewLocal(), nil
+	}
+	client, err := connect(cfg, app, cliHost)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Hosts returns the servers an app reads logs from. For a kubectl app
+// with a pod selector and no servers listed, these are the pods matching
+// the selector now.
+func Hosts(cfg *config.Config, app *config.App) ([]string, error) {
+	return hosts(cfg, app, ssh.ConnectServer)
+}
+
+// HostsInteractive is Hosts for command-line use, asking the user to
+// confirm the key of an unknown cli-host
+func HostsInteractive(cfg *config.Config, app *config.App) ([]string, error) {
+	return hosts(cfg, app, ssh.ConnectServerInteractive)
+}
+
+func hosts(cfg *config.Config, app *config.App, connect connectFunc) ([]string, error) {
+	if !app.SelectsPods() {
+		return app.Hosts(), nil
+	}
+
+	run, err := openRunner(cfg, app, app.CLIHost, connect)
+	if err != nil {
+		return nil, err
+	}
+	defer run.Close()
+
+	pods, err := source.ListPods(run, app.Namespace, app.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods match %s in app %s", app.Selector, app.Name)
+	}
+	return pods, nil
+}
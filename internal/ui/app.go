@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/ssh"
 )
 
 // AddAppInteractive shows interactive UI for adding an app
@@ -43,17 +44,47 @@ func AddAppInteractive() error {
 	fmt.Print("Log file path (e.g., /logs/testapp/testapp.log): ")
 	fmt.Scanln(&app.LogPath)
 
-	// Log pattern for rolling files
-	fmt.Print("Log filename pattern with {date} placeholder (e.g., testapp-{date}.log or testapp.log-{date}): ")
-	fmt.Scanln(&app.LogPattern)
+	// Rotation scheme
+	fmt.Println("\nHow are older logs named?")
+	fmt.Println("  date     -> testapp-2025-09-10.log (pattern with a date)")
+	fmt.Println("  numeric  -> testapp.log.1, testapp.log.2.gz (logrotate numbering)")
+	fmt.Print("Rotation [date]: ")
+	fmt.Scanln(&app.Rotation)
+	switch app.Rotation {
+	case "", config.RotationDate:
+		app.Rotation = ""
+	case config.RotationNumeric:
+	default:
+		return fmt.Errorf("invalid rotation: %s", app.Rotation)
+	}
+
+	if !app.NumericRotation() {
+		// Log pattern for rolling files
+		fmt.Print("Log filename pattern with {date} placeholder (e.g., testapp-{date}.log or testapp.log-{date}): ")
+		fmt.Scanln(&app.LogPattern)
+
+		// Date format
+		fmt.Println("\nDate format examples:")
+		fmt.Println("  2006-01-02  -> 2025-09-10")
+		fmt.Println("  20060102    -> 20250910")
+		fmt.Println("  02-01-2006  -> 10-09-2025")
+		fmt.Print("Date format (Go format): ")
+		fmt.Scanln(&app.DateFormat)
+	}
 
-	// Date format
-	fmt.Println("\nDate format examples:")
-	fmt.Println("  2006-01-02  -> 2025-09-10")
-	fmt.Println("  20060102    -> 20250910")
-	fmt.Println("  02-01-2006  -> 10-09-2025")
-	fmt.Print("Date format (Go format): ")
-	fmt.Scanln(&app.DateFormat)
+	// Timezone for dates in file names and timestamps
+	fmt.Print("Timezone, e.g. UTC or Asia/Colombo (empty for local time): ")
+	fmt.Scanln(&app.Timezone)
+	if err := app.CheckTimezones(); err != nil {
+		return err
+	}
+
+	// Jump hosts
+	fmt.Println("\nJump hosts, comma separated in connection order, as [user-ref@]host[:port]")
+	fmt.Print("Jump hosts (empty for direct connection): ")
+	var jump string
+	fmt.Scanln(&jump)
+	app.Jump = config.ParseHops(jump)
 
 	// Servers
 	fmt.Println("\nEnter server IPs (one per line, empty line to finish):")
@@ -68,7 +99,7 @@ func AddAppInteractive() error {
 		if server == "" {
 			break
 		}
-		app.Servers = append(app.Servers, server)
+		app.Servers = append(app.Servers, config.Server{Host: server})
 	}
 
 	if len(app.Servers) == 0 {
@@ -118,6 +149,23 @@ func UpdateAppInteractive(appName string) error {
 		app.LogPath = input
 	}
 
+	rotation := app.Rotation
+	if rotation == "" {
+		rotation = config.RotationDate
+	}
+	fmt.Printf("Rotation (%s/%s) [%s]: ", config.RotationDate, config.RotationNumeric, rotation)
+	input = ""
+	fmt.Scanln(&input)
+	switch input {
+	case "":
+	case config.RotationDate:
+		app.Rotation = ""
+	case config.RotationNumeric:
+		app.Rotation = input
+	default:
+		return fmt.Errorf("invalid rotation: %s", input)
+	}
+
 	fmt.Printf("Log pattern [%s]: ", app.LogPattern)
 	input = ""
 	fmt.Scanln(&input)
@@ -132,16 +180,42 @@ func UpdateAppInteractive(appName string) error {
 		app.DateFormat = input
 	}
 
+	timezone := app.Timezone
+	if timezone == "" {
+		timezone = "local"
+	}
+	fmt.Printf("Timezone [%s] (\"local\" to clear): ", timezone)
+	input = ""
+	fmt.Scanln(&input)
+	if input == "local" {
+		app.Timezone = ""
+	} else if input != "" {
+		app.Timezone = input
+		if err := app.CheckTimezones(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Jump hosts [%s] (\"%s\" to clear): ", config.FormatHops(app.Jump), config.NoJump)
+	input = ""
+	fmt.Scanln(&input)
+	if input == config.NoJump {
+		app.Jump = nil
+	} else if input != "" {
+		app.Jump = config.ParseHops(input)
+	}
+
 	fmt.Println("\nCurrent servers:")
 	for _, server := range app.Servers {
-		fmt.Printf("  - %s\n", server)
+		fmt.Printf("  - %s\n", server.Host)
 	}
 	fmt.Print("Update servers? (y/n): ")
 	reader := bufio.NewReader(os.Stdin)
 	input, _ = reader.ReadString('\n')
 	input = strings.TrimSpace(input)
 	if strings.ToLower(input) == "y" {
-		app.Servers = []string{}
+		previous := *app
+		app.Servers = []config.Server{}
 		fmt.Println("Enter new servers (empty line to finish):")
 		for {
 			fmt.Print("Server IP: ")
@@ -153,7 +227,8 @@ func UpdateAppInteractive(appName string) error {
 			if server == "" {
 				break
 			}
-			app.Servers = append(app.Servers, server)
+			// Keep per-server settings for hosts that are entered again
+			app.Servers = append(app.Servers, previous.GetServer(server))
 		}
 	}
 
@@ -187,9 +262,45 @@ func ListApps() error {
 		fmt.Printf("Name: %s\n", app.Name)
 		fmt.Printf("  User: %s\n", app.UserRef)
 		fmt.Printf("  Path: %s\n", app.LogPath)
-		fmt.Printf("  Pattern: %s\n", app.LogPattern)
-		fmt.Printf("  Date Format: %s\n", app.DateFormat)
-		fmt.Printf("  Servers: %s\n", strings.Join(app.Servers, ", "))
+		if app.NumericRotation() {
+			fmt.Printf("  Rotation: %s\n", app.Rotation)
+		} else {
+			fmt.Printf("  Pattern: %s\n", app.LogPattern)
+			fmt.Printf("  Date Format: %s\n", app.DateFormat)
+		}
+		if app.Timezone != "" {
+			fmt.Printf("  Timezone: %s\n", app.Timezone)
+		}
+		if app.Transport != "" {
+			fmt.Printf("  Transport: %s\n", app.Transport)
+		}
+		if app.CLIHost != "" {
+			fmt.Printf("  CLI Host: %s\n", app.CLIHost)
+		}
+		if app.Namespace != "" {
+			fmt.Printf("  Namespace: %s\n", app.Namespace)
+		}
+		if app.Container != "" {
+			fmt.Printf("  Container: %s\n", app.Container)
+		}
+		if app.Selector != "" {
+			fmt.Printf("  Selector: %s\n", app.Selector)
+		}
+		if len(app.Jump) > 0 {
+			fmt.Printf("  Jump: %s\n", config.FormatHops(app.Jump))
+		}
+		fmt.Printf("  Servers: %s\n", strings.Join(app.Hosts(), ", "))
+		for _, server := range app.Servers {
+			if server.Jump != "" {
+				fmt.Printf("    %s jump: %s\n", server.Host, server.Jump)
+			}
+			if server.Timezone != "" {
+				fmt.Printf("    %s timezone: %s\n", server.Host, server.Timezone)
+			}
+			if server.CLIHost != "" {
+				fmt.Printf("    %s cli-host: %s\n", server.Host, server.CLIHost)
+			}
+		}
 		fmt.Println()
 	}
 
@@ -213,6 +324,14 @@ func ListUsers() error {
 	for _, user := range cfg.Users.Users {
 		fmt.Printf("Name: %s\n", user.Name)
 		fmt.Printf("  Username: %s\n", user.Username)
+		if len(user.Auth) > 0 {
+			fmt.Printf("  Auth: %s\n", strings.Join(user.Auth, ", "))
+		} else {
+			fmt.Printf("  Auth: %s (default)\n", strings.Join(ssh.DefaultAuthMethods, ", "))
+		}
+		if len(user.KeyFiles) > 0 {
+			fmt.Printf("  Keys: %s\n", strings.Join(user.KeyFiles, ", "))
+		}
 		fmt.Println()
 	}
 
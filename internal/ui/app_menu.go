@@ -105,9 +105,12 @@ func (m AppManagementModel) handleSelection() (tea.Model, tea.Cmd) {
 	case "list":
 		if m.cursor < len(m.apps) {
 			app := m.apps[m.cursor]
+			naming := fmt.Sprintf("📝 Pattern: %s | 📅 Format: %s", app.LogPattern, app.DateFormat)
+			if app.NumericRotation() {
+				naming = "🔢 Rotation: numeric"
+			}
 			m.message = infoStyle.Render(
-				fmt.Sprintf("📱 %s | 🖥️  %d servers | 📝 Pattern: %s | 📅 Format: %s",
-					app.Name, len(app.Servers), app.LogPattern, app.DateFormat))
+				fmt.Sprintf("📱 %s | 🖥️  %s | %s", app.Name, serversLabel(&app), naming))
 		}
 
 	case "delete":
@@ -201,7 +204,7 @@ func (m AppManagementModel) renderList() string {
 
 	for i, app := range m.apps {
 		cursor := "  "
-		line := fmt.Sprintf("📱 %s (%d servers)", app.Name, len(app.Servers))
+		line := fmt.Sprintf("📱 %s (%s)", app.Name, serversLabel(&app))
 
 		if i == m.cursor {
 			cursor = cursorStyle.Render("▶ ")
@@ -246,3 +249,12 @@ func (m AppManagementModel) renderDelete() string {
 
 	return menuBoxStyle.Render(content.String())
 }
+
+// serversLabel says where an app's logs come from, for app lists: how many
+// servers, or the selector finding its pods
+func serversLabel(app *config.App) string {
+	if app.SelectsPods() {
+		return "pods matching " + app.Selector
+	}
+	return fmt.Sprintf("%d servers", len(app.Hosts()))
+}
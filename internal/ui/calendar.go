@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	calendarTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FAFAFA")).
+				Bold(true)
+
+	calendarWeekdayStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#626262"))
+
+	// Days with a log on every server
+	calendarAvailableStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#04B575")).
+				Bold(true)
+
+	// Days with a log on some servers only
+	calendarPartialStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFA500"))
+
+	calendarMissingStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#444444"))
+
+	calendarSelectedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FAFAFA")).
+				Background(lipgloss.Color("#7D56F4")).
+				Bold(true)
+)
+
+// hasCalendar reports whether the date step shows which days have logs.
+// Rotations by number are listed instead, and container logs are not kept
+// in dated files at all.
+func (m LogSelectionModel) hasCalendar() bool {
+	return !m.selectedApp.NumericRotation() && !m.selectedApp.Containers()
+}
+
+// moveCalendar moves the calendar's selected day and makes it the date to
+// open
+func (m *LogSelectionModel) moveCalendar(key string) {
+	switch key {
+	case "left":
+		m.calDate = m.calDate.AddDate(0, 0, -1)
+	case "right":
+		m.calDate = m.calDate.AddDate(0, 0, 1)
+	case "up":
+		m.calDate = m.calDate.AddDate(0, 0, -7)
+	case "down":
+		m.calDate = m.calDate.AddDate(0, 0, 7)
+	case "pgup":
+		m.calDate = addMonths(m.calDate, -1)
+	case "pgdown":
+		m.calDate = addMonths(m.calDate, 1)
+	}
+	m.dateInput = m.calDate.Format("2006-01-02")
+}
+
+// addMonths moves a date by whole months, keeping to the last day of a
+// shorter month rather than spilling into the next
+func addMonths(date time.Time, n int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(n), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), lastDay)-1)
+}
+
+// dayCounts counts, for each day, the servers that have a log for it, and
+// returns how many servers could be scanned
+func (m LogSelectionModel) dayCounts() (map[string]int, int) {
+	counts := make(map[string]int)
+	scanned := 0
+	for _, inv := range m.inventory {
+		if inv.err != nil {
+			continue
+		}
+		scanned++
+		for _, log := range inv.logs {
+			counts[log.Date.Format("2006-01-02")]++
+		}
+	}
+	return counts, scanned
+}
+
+// renderCalendar draws the month of the selected day, marking the days
+// with logs on all servers, on some, or on none
+func (m LogSelectionModel) renderCalendar() string {
+	var b strings.Builder
+
+	month := time.Date(m.calDate.Year(), m.calDate.Month(), 1, 0, 0, 0, 0, m.calDate.Location())
+	b.WriteString(calendarTitleStyle.Render(fmt.Sprintf("%-20s", month.Format("January 2006"))))
+	b.WriteString("\n")
+	b.WriteString(calendarWeekdayStyle.Render("Mo Tu We Th Fr Sa Su"))
+	b.WriteString("\n")
+
+	counts, scanned := m.dayCounts()
+	selected := m.calDate.Format("2006-01-02")
+
+	// Weeks start on Monday
+	offset := (int(month.Weekday()) + 6) % 7
+	b.WriteString(strings.Repeat("   ", offset))
+	for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		cell := fmt.Sprintf("%2d", day.Day())
+
+		switch {
+		case key == selected:
+			cell = calendarSelectedStyle.Render(cell)
+		case m.listing || scanned == 0:
+			// Availability not known (yet)
+		case counts[key] == scanned:
+			cell = calendarAvailableStyle.Render(cell)
+		case counts[key] > 0:
+			cell = calendarPartialStyle.Render(cell)
+		default:
+			cell = calendarMissingStyle.Render(cell)
+		}
+		b.WriteString(cell)
+
+		if day.Weekday() == time.Sunday {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(" ")
+		}
+	}
+	b.WriteString("\n")
+
+	// Legend, or how the scan is going
+	switch {
+	case m.listing:
+		b.WriteString(logStatsStyle.Render("⏳ Scanning servers for logs..."))
+	case scanned == 0:
+		b.WriteString(errorStyle.Render("Could not list logs on any server"))
+	default:
+		b.WriteString(calendarAvailableStyle.Render("■") + " all servers  ")
+		if scanned > 1 {
+			b.WriteString(calendarPartialStyle.Render("■") + " some servers  ")
+		}
+		b.WriteString(calendarMissingStyle.Render("■") + " no log")
+		if n := len(m.inventory) - scanned; n > 0 {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("  (%d server(s) failed, Tab for details)", n)))
+		}
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ListKnownHosts displays the host keys logx verifies against
+func ListKnownHosts() error {
+	hosts, err := ssh.ListKnownHosts()
+	if err != nil {
+		return err
+	}
+
+	if len(hosts) == 0 {
+		fmt.Println("No known hosts.")
+		return nil
+	}
+
+	fmt.Println("Known hosts:")
+	fmt.Println()
+	lastFile := ""
+	for _, host := range hosts {
+		if host.File != lastFile {
+			fmt.Printf("%s\n", host.File)
+			lastFile = host.File
+		}
+		fmt.Printf("  %s\n", strings.Join(host.Hosts, ", "))
+		fmt.Printf("    %s %s\n", host.KeyType, host.Fingerprint)
+	}
+
+	return nil
+}
+
+// TrustHostInteractive fetches a host's key, shows its fingerprint and
+// records it in the logx known_hosts file once confirmed. The host is
+// reached as logx connects to it: resolved through ~/.ssh/config and, when
+// appName is set, that app's user and jump hosts. Unknown jump hosts are
+// confirmed on the way. It returns the address the key is recorded under.
+func TrustHostInteractive(host, appName string) (string, error) {
+	var target ssh.Endpoint
+	var jumps []ssh.Endpoint
+	var err error
+	if appName != "" {
+		cfg, loadErr := config.Load()
+		if loadErr != nil {
+			return "", loadErr
+		}
+		app, appErr := cfg.GetApp(appName)
+		if appErr != nil {
+			return "", appErr
+		}
+		target, jumps, err = ssh.ResolveServer(cfg, app, host)
+	} else {
+		target, jumps, err = ssh.ResolveHost(host)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		addr, key, err := ssh.FetchHostKey(target, jumps)
+
+		var unknown *ssh.UnknownHostError
+		if errors.As(err, &unknown) {
+			// A jump host on the way; trust it first
+			if err := confirmAndTrust(unknown.Host, unknown.Key); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return addr, confirmAndTrust(addr, key)
+	}
+}
+
+func confirmAndTrust(host string, key gossh.PublicKey) error {
+	ok, err := ssh.ConfirmHostKey(host, key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("host key for %s not trusted", host)
+	}
+	return ssh.TrustHost(host, key)
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/transport"
+	"github.com/jatsandaruwan/logx/internal/viewer"
+)
+
+// inventoryPageSize is how many lines of the inventory are shown at once
+const inventoryPageSize = 16
+
+// hostInventory is the dated logs found on one server
+type hostInventory struct {
+	host string
+	logs []source.DatedLog // Oldest first; shown newest first
+	err  error
+}
+
+type inventoryMsg struct {
+	hosts []hostInventory
+}
+
+// inventoryRow is a log that can be picked on the inventory screen
+type inventoryRow struct {
+	host string
+	log  source.DatedLog
+}
+
+// listInventory scans the log directory of the chosen server, or of all
+// of them at once, for dated logs
+func (m LogSelectionModel) listInventory() tea.Cmd {
+	hosts := m.servers[1:]
+	if m.serverIdx >= 0 {
+		hosts = []string{m.servers[m.serverIdx+1]}
+	}
+
+	return func() tea.Msg {
+		app := m.selectedApp
+		msg := inventoryMsg{hosts: make([]hostInventory, len(hosts))}
+
+		var wg sync.WaitGroup
+		for i, host := range hosts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				msg.hosts[i] = hostInventory{host: host}
+
+				client, err := transport.Open(m.config, app, host)
+				if err != nil {
+					msg.hosts[i].err = err
+					return
+				}
+				defer client.Close()
+
+				msg.hosts[i].logs, msg.hosts[i].err = source.DatedLogs(client,
+					app.LogPath, app.LogPattern, app.DateFormat, app.Location(host))
+			}()
+		}
+		wg.Wait()
+
+		return msg
+	}
+}
+
+// inventoryRows returns the logs of the inventory in the order shown
+func (m LogSelectionModel) inventoryRows() []inventoryRow {
+	var rows []inventoryRow
+	for _, inv := range m.inventory {
+		for i := len(inv.logs) - 1; i >= 0; i-- {
+			rows = append(rows, inventoryRow{host: inv.host, log: inv.logs[i]})
+		}
+	}
+	return rows
+}
+
+// pickInventoryDate opens the logs of the date under the cursor
+func (m LogSelectionModel) pickInventoryDate() (tea.Model, tea.Cmd) {
+	rows := m.inventoryRows()
+	if m.cursor >= len(rows) {
+		return m, nil
+	}
+
+	m.calDate = rows[m.cursor].log.Date
+	m.dateInput = m.calDate.Format("2006-01-02")
+	m.mode = "date"
+	m.message = ""
+	m.loading = true
+	return m, m.loadLogs()
+}
+
+// renderInventory lists the dated logs found on each server, scrolled to
+// keep the cursor in view
+func (m LogSelectionModel) renderInventory() string {
+	content := fmt.Sprintf("Available logs for %s:\n\n", logFocusedStyle.Render(m.selectedApp.Name))
+	if m.listing {
+		return logMenuBoxStyle.Width(96).Render(content + logStatsStyle.Render("⏳ Scanning servers..."))
+	}
+
+	var lines []string
+	cursorLine, row := 0, 0
+	for _, inv := range m.inventory {
+		lines = append(lines, logServerTagStyle.Render(inv.host))
+		switch {
+		case inv.err != nil:
+			lines = append(lines, "  "+errorStyle.Render(fmt.Sprintf("Error: %v", inv.err)))
+		case len(inv.logs) == 0:
+			lines = append(lines, "  "+logBlurredStyle.Render("No dated logs found: "+m.selectedApp.LogPattern))
+		}
+
+		for i := len(inv.logs) - 1; i >= 0; i-- {
+			log := inv.logs[i]
+			cursor := " "
+			line := fmt.Sprintf("%s  %-32s %s  %9s  %s", log.Date.Format("2006-01-02"), log.Name,
+				log.ModTime.In(m.selectedApp.Location(inv.host)).Format("2006-01-02 15:04"),
+				viewer.FormatSize(log.Size), strings.TrimPrefix(compress.Ext(log.Name), "."))
+			if row == m.cursor {
+				cursor = logCursorStyle.Render("▶")
+				line = logFocusedStyle.Render(line)
+				cursorLine = len(lines)
+			} else {
+				line = logBlurredStyle.Render(line)
+			}
+			lines = append(lines, fmt.Sprintf("%s %s", cursor, line))
+			row++
+		}
+
+		if n := len(viewer.MissingDays(inv.logs)); n > 0 {
+			lines = append(lines, "  "+warningStyle.Render(fmt.Sprintf("%d day(s) missing in between", n)))
+		}
+		lines = append(lines, "")
+	}
+
+	// Scroll so the cursor stays on screen
+	start := 0
+	if cursorLine >= inventoryPageSize {
+		start = cursorLine - inventoryPageSize + 1
+	}
+	end := min(start+inventoryPageSize, len(lines))
+
+	return logMenuBoxStyle.Width(96).Render(content + strings.Join(lines[start:end], "\n"))
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,8 +11,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/dates"
+	"github.com/jatsandaruwan/logx/internal/source"
 	"github.com/jatsandaruwan/logx/internal/ssh"
-	"github.com/jatsandaruwan/logx/internal/vault"
+	"github.com/jatsandaruwan/logx/internal/transport"
 	"github.com/jatsandaruwan/logx/internal/viewer"
 )
 
@@ -18,21 +22,40 @@ type LogSelectionModel struct {
 	cursor      int
 	config      *config.Config
 	apps        []config.App
-	mode        string // "select", "date", "server", "loading", "view"
+	mode        string // "select", "date", "server", "inventory", "loading", "view", "hostkey"
 	selectedApp *config.App
 	dateInput   string
 	servers     []string
 	serverIdx   int
 	loading     bool
 	message     string
-	logContent  []string
+	unknownHost *ssh.UnknownHostError
+	// Hosts whose keys were rejected while loading all servers
+	rejectedHosts map[string]bool
+
+	// Numbered rotations of the log, for apps that don't rotate by date
+	generations []source.Generation
+	genHost     string
+	genErr      error
+	listing     bool
+
+	// Dated logs found on each server, for apps that rotate by date, and
+	// the day picked on the calendar
+	inventory []hostInventory
+	calDate   time.Time
+
+	// Pattern to grep for on the servers, so only matching lines are
+	// fetched; typed into while filterFocus is set
+	filterInput string
+	filterFocus bool
 }
 
 func NewLogSelectionMenu(cfg *config.Config) LogSelectionModel {
 	return LogSelectionModel{
-		config: cfg,
-		apps:   cfg.Apps.Apps,
-		mode:   "select",
+		config:        cfg,
+		apps:          cfg.Apps.Apps,
+		mode:          "select",
+		rejectedHosts: make(map[string]bool),
 	}
 }
 
@@ -43,6 +66,26 @@ func (m LogSelectionModel) Init() tea.Cmd {
 func (m LogSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Dates can be typed in words, so the date step takes any text
+		if m.mode == "date" && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) {
+			if m.filterFocus {
+				m.filterInput += string(msg.Runes)
+				return m, nil
+			}
+			m.dateInput += string(msg.Runes)
+			m.previewDate()
+			return m, nil
+		}
+
+		// The arrow keys move around the calendar of dated logs
+		if m.mode == "date" && !m.selectedApp.NumericRotation() {
+			switch msg.String() {
+			case "left", "right", "up", "down", "pgup", "pgdown":
+				m.moveCalendar(msg.String())
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
@@ -52,6 +95,12 @@ func (m LogSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				mainMenu, _ := NewMainMenu()
 				return mainMenu, nil
 			}
+			if m.mode == "inventory" {
+				// Back to typing a date
+				m.mode = "date"
+				m.cursor = 0
+				return m, nil
+			}
 			m.mode = "select"
 			m.cursor = 0
 			m.message = ""
@@ -61,6 +110,10 @@ func (m LogSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.cursor > 0 {
 				m.cursor--
 			}
+			if m.mode == "date" {
+				// Picking a rotation replaces a typed date
+				m.dateInput = ""
+			}
 
 		case "down", "j":
 			maxCursor := 0
@@ -68,7 +121,12 @@ func (m LogSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "select":
 				maxCursor = len(m.apps)
 			case "server":
-				maxCursor = len(m.servers)
+				maxCursor = len(m.servers) - 1
+			case "date":
+				maxCursor = len(m.generations) - 1
+				m.dateInput = ""
+			case "inventory":
+				maxCursor = len(m.inventoryRows()) - 1
 			}
 			if m.cursor < maxCursor {
 				m.cursor++
@@ -77,28 +135,76 @@ func (m LogSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter":
 			return m.handleSelection()
 
-		case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "-":
+		case "tab":
+			if m.mode == "date" && m.hasCalendar() {
+				// See which days have logs before picking one
+				m.mode = "inventory"
+				m.cursor = 0
+				m.message = ""
+				if m.inventory == nil && !m.listing {
+					m.listing = true
+					return m, m.listInventory()
+				}
+			}
+
+		case "y", "n":
+			if m.mode == "hostkey" {
+				return m.handleHostKey(msg.String() == "y")
+			}
+
+		case "ctrl+f":
 			if m.mode == "date" {
-				m.dateInput += msg.String()
+				m.filterFocus = !m.filterFocus
 			}
 
 		case "backspace":
-			if m.mode == "date" && len(m.dateInput) > 0 {
+			if m.mode == "date" && m.filterFocus {
+				if len(m.filterInput) > 0 {
+					m.filterInput = m.filterInput[:len(m.filterInput)-1]
+				}
+			} else if m.mode == "date" && len(m.dateInput) > 0 {
 				m.dateInput = m.dateInput[:len(m.dateInput)-1]
 			}
 		}
 
+	case generationsMsg:
+		if m.mode != "date" {
+			// Left the date step while listing
+			return m, nil
+		}
+		m.listing = false
+		m.generations, m.genErr = msg.generations, msg.err
+		m.cursor = 0
+		m.previewDate()
+
+	case inventoryMsg:
+		if m.mode != "date" && m.mode != "inventory" {
+			// Left the date step while scanning
+			return m, nil
+		}
+		m.listing = false
+		m.inventory = msg.hosts
+
 	case loadingMsg:
 		m.loading = false
-		if msg.err != nil {
+		var unknown *ssh.UnknownHostError
+		if errors.As(msg.err, &unknown) {
+			m.unknownHost = unknown
+			m.mode = "hostkey"
+			m.message = ""
+		} else if msg.err != nil {
 			m.message = errorStyle.Render(fmt.Sprintf("Error: %v", msg.err))
 			m.mode = "select"
 		} else {
-			m.logContent = msg.content
 			m.mode = "view"
-			// Launch internal viewer
+			// Launch internal viewer, keeping the connections open for follow mode
 			return m, func() tea.Msg {
-				viewer.OpenInternalViewer(msg.content, msg.server, msg.logFile)
+				defer func() {
+					for _, client := range msg.clients {
+						client.Close()
+					}
+				}()
+				viewer.OpenMultiViewer(msg.buffers)
 				return backToMenuMsg{}
 			}
 		}
@@ -112,14 +218,48 @@ func (m LogSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 type loadingMsg struct {
-	content []string
-	server  string
-	logFile string
+	buffers []viewer.LogBuffer // One per server, including failed ones
+	clients []source.Source    // Left open for follow mode; closed after the viewer exits
 	err     error
 }
 
 type backToMenuMsg struct{}
 
+type generationsMsg struct {
+	generations []source.Generation
+	err         error
+}
+
+// handleHostKey records or rejects the key of a host seen for the first time
+func (m LogSelectionModel) handleHostKey(trust bool) (tea.Model, tea.Cmd) {
+	unknown := m.unknownHost
+	m.unknownHost = nil
+
+	if !trust {
+		if m.serverIdx < 0 {
+			// Show the other servers, with this one failing inline
+			m.rejectedHosts[unknown.Host] = true
+			m.mode = "date"
+			m.loading = true
+			return m, m.loadLogs()
+		}
+		m.mode = "select"
+		m.cursor = 0
+		m.message = errorStyle.Render(fmt.Sprintf("Host key for %s rejected", unknown.Host))
+		return m, nil
+	}
+
+	if err := ssh.TrustHost(unknown.Host, unknown.Key); err != nil {
+		m.mode = "select"
+		m.message = errorStyle.Render(fmt.Sprintf("Error: %v", err))
+		return m, nil
+	}
+
+	m.mode = "date"
+	m.loading = true
+	return m, m.loadLogs()
+}
+
 func (m LogSelectionModel) handleSelection() (tea.Model, tea.Cmd) {
 	switch m.mode {
 	case "select":
@@ -128,91 +268,263 @@ func (m LogSelectionModel) handleSelection() (tea.Model, tea.Cmd) {
 			mainMenu, _ := NewMainMenu()
 			return mainMenu, nil
 		}
-		m.selectedApp = &m.apps[m.cursor]
-		m.servers = append([]string{"All Servers"}, m.selectedApp.Servers...)
+		app := &m.apps[m.cursor]
+		hosts, err := transport.Hosts(m.config, app)
+		if err != nil {
+			m.message = errorStyle.Render(fmt.Sprintf("Error: %v", err))
+			return m, nil
+		}
+		m.selectedApp = app
+		m.message = ""
+		m.filterInput, m.filterFocus = "", false
+		m.servers = append([]string{"All Servers"}, hosts...)
 		m.mode = "server"
 		m.cursor = 0
 
 	case "server":
 		if m.cursor == 0 {
-			// All servers - just pick first for now
-			m.serverIdx = 0
+			// All servers
+			m.serverIdx = -1
 		} else {
 			m.serverIdx = m.cursor - 1
 		}
 		m.mode = "date"
-		m.dateInput = time.Now().Format("2006-01-02")
+		host := ""
+		if m.serverIdx >= 0 {
+			host = m.servers[m.serverIdx+1]
+		}
+		now := time.Now().In(m.selectedApp.Location(host))
+		m.dateInput = now.Format("2006-01-02")
+
+		if m.selectedApp.NumericRotation() {
+			// Offer the rotations of the chosen server, or of the first one
+			m.dateInput = ""
+			m.cursor = 0
+			m.generations, m.genErr = nil, nil
+			m.genHost = m.servers[max(m.serverIdx, 0)+1]
+			m.listing = true
+			return m, m.listGenerations()
+		}
+
+		// Show which days have logs on the calendar
+		m.calDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		m.inventory = nil
+		if !m.hasCalendar() {
+			return m, nil
+		}
+		m.listing = true
+		return m, m.listInventory()
 
 	case "date":
+		if m.selectedApp.NumericRotation() && m.dateInput == "" && len(m.generations) == 0 {
+			m.message = errorStyle.Render("No rotated log to pick; type a date instead")
+			return m, nil
+		}
+		m.message = ""
+
 		// Load logs
 		m.loading = true
 		return m, m.loadLogs()
+
+	case "inventory":
+		return m.pickInventoryDate()
 	}
 
 	return m, nil
 }
 
+// listGenerations finds the numbered rotations of the app's log on one
+// server, so one can be picked
+func (m LogSelectionModel) listGenerations() tea.Cmd {
+	return func() tea.Msg {
+		client, err := transport.Open(m.config, m.selectedApp, m.genHost)
+		if err != nil {
+			return generationsMsg{err: err}
+		}
+		defer client.Close()
+
+		gens, err := source.Generations(client, m.selectedApp.LogPath)
+		return generationsMsg{generations: gens, err: err}
+	}
+}
+
+// previewDate moves the calendar to a typed date, or the cursor to the
+// rotation holding it
+func (m *LogSelectionModel) previewDate() {
+	if !m.selectedApp.NumericRotation() {
+		if date, err := dates.Parse(m.dateInput, time.Now().In(m.calDate.Location())); err == nil {
+			m.calDate = date
+		}
+		return
+	}
+
+	date, err := dates.Parse(m.dateInput, time.Now().In(m.selectedApp.Location(m.genHost)))
+	if err != nil {
+		return
+	}
+	if gen, ok := source.GenerationForDate(m.generations, date); ok {
+		for i := range m.generations {
+			if m.generations[i].Index == gen.Index {
+				m.cursor = i
+			}
+		}
+	}
+}
+
 func (m LogSelectionModel) loadLogs() tea.Cmd {
 	return func() tea.Msg {
-		// Get user credentials
-		user, err := m.config.GetUser(m.selectedApp.UserRef)
+		opts, err := transport.FetchOptionsFor(m.config)
 		if err != nil {
 			return loadingMsg{err: err}
 		}
+		opts.KeepOpen = true
 
-		creds, err := vault.Get(user.ID)
-		if err != nil {
+		if err := m.selectedApp.CheckTimezones(); err != nil {
 			return loadingMsg{err: err}
 		}
 
-		// Parse date
-		logDate, err := time.Parse("2006-01-02", m.dateInput)
-		if err != nil {
-			return loadingMsg{err: fmt.Errorf("invalid date format")}
+		servers := m.servers[1:]
+		if m.serverIdx >= 0 {
+			servers = []string{m.servers[m.serverIdx+1]}
 		}
 
-		// Format the log filename
-		formattedDate := logDate.Format(m.selectedApp.DateFormat)
-		logFileName := strings.ReplaceAll(m.selectedApp.LogPattern, "{date}", formattedDate)
-		logFilePath := m.selectedApp.LogPath
-		if strings.Contains(logFilePath, "/") {
-			logFilePath = logFilePath[:strings.LastIndex(logFilePath, "/")+1] + logFileName
+		var logFilePath string
+		var window viewer.TimeWindow
+		switch {
+		case strings.Contains(m.dateInput, ".."):
+			// A time window, FROM..TO, which may span several files
+			from, to, _ := strings.Cut(m.dateInput, "..")
+			window, err = viewer.ParseTimeWindow(from, to, m.selectedApp.Location(""))
+			if err != nil {
+				return loadingMsg{err: err}
+			}
+			logFilePath, opts.Locate = viewer.WindowLog(m.selectedApp, window)
+
+		case m.selectedApp.NumericRotation() && m.dateInput == "":
+			if m.cursor >= len(m.generations) {
+				return loadingMsg{err: fmt.Errorf("pick a rotated log or type a date")}
+			}
+			logFilePath = m.selectedApp.LogPath
+			opts.Locate = source.LocateGeneration(logFilePath, m.generations[m.cursor].Index)
+
+		case m.selectedApp.Containers():
+			// The day's lines, trimmed by timestamp when the CLI can't stop
+			// at the day's end
+			window, err = viewer.ParseTimeWindow(m.dateInput, m.dateInput, m.selectedApp.Location(""))
+			if err != nil {
+				return loadingMsg{err: err}
+			}
+			logFilePath, opts.Locate = viewer.WindowLog(m.selectedApp, window)
+
+		default:
+			// Each server resolves the date on its own clock
+			logFilePath, opts.Locate, err = viewer.DateLog(m.selectedApp, servers, m.dateInput)
+			if err != nil {
+				return loadingMsg{err: err}
+			}
 		}
 
-		// Connect to server
-		server := m.selectedApp.Servers[m.serverIdx]
-		client, err := ssh.Connect(server, creds.Username, creds.Password)
-		if err != nil {
-			return loadingMsg{err: fmt.Errorf("failed to connect to %s: %w", server, err)}
+		if m.filterInput != "" {
+			return m.grepLogs(servers, logFilePath, opts.Locate, window)
 		}
-		defer client.Close()
 
-		// Check if file exists
-		exists, err := client.FileExists(logFilePath)
-		if err != nil || !exists {
-			return loadingMsg{err: fmt.Errorf("log file not found: %s", logFilePath)}
+		// Connect to the servers and download the file
+		results := transport.FetchAll(context.Background(), m.config, m.selectedApp, servers, logFilePath, opts)
+
+		var msg loadingMsg
+		for _, result := range results {
+			if result.Source != nil {
+				msg.clients = append(msg.clients, result.Source)
+			}
+		}
+		fail := func(err error) loadingMsg {
+			for _, client := range msg.clients {
+				client.Close()
+			}
+			return loadingMsg{err: err}
 		}
 
-		// Download and read file
-		localPath, err := client.DownloadFile(logFilePath)
-		if err != nil {
-			return loadingMsg{err: fmt.Errorf("failed to download: %w", err)}
+		// Ask about unknown host keys before showing anything
+		for _, result := range results {
+			var unknown *ssh.UnknownHostError
+			if errors.As(result.Err, &unknown) && !m.rejectedHosts[unknown.Host] {
+				return fail(unknown)
+			}
 		}
 
-		// Read content
-		contentBytes, err := os.ReadFile(localPath)
-		if err != nil {
-			return loadingMsg{err: fmt.Errorf("failed to read file: %w", err)}
+		loaded := 0
+		for _, result := range results {
+			buffer := viewer.LogBuffer{
+				Server:   result.Host,
+				LogFile:  viewer.LogFileLabel(result),
+				Location: m.selectedApp.Location(result.Host),
+			}
+
+			switch {
+			case errors.Is(result.Err, os.ErrNotExist) && opts.Locate == nil:
+				buffer.Err = fmt.Errorf("log file not found: %s", logFilePath)
+			case errors.Is(result.Err, os.ErrNotExist):
+				buffer.Err = result.Err
+			case result.Err != nil:
+				buffer.Err = fmt.Errorf("failed to fetch from %s: %w", result.Host, result.Err)
+			}
+
+			if buffer.Err == nil {
+				// Read content, joining and trimming files for a time window
+				contentBytes, offset, err := viewer.ReadFetched(result, window, buffer.Location)
+				if err != nil {
+					buffer.Err = err
+				} else {
+					buffer.Content = strings.Split(string(contentBytes), "\n")
+					// Rotated copies and closed windows are no longer written to
+					if viewer.CanFollow(m.selectedApp, result.RemotePath) && window.Until.IsZero() {
+						client, remotePath, inode := result.Source, result.RemotePath, result.Inode
+						buffer.Follow = func(ctx context.Context, events chan<- source.FollowEvent) error {
+							return client.Follow(ctx, remotePath, offset, inode, events)
+						}
+					}
+					loaded++
+				}
+			}
+
+			msg.buffers = append(msg.buffers, buffer)
+		}
+
+		// With a single server there is nothing else to show
+		if loaded == 0 && len(msg.buffers) == 1 {
+			return fail(msg.buffers[0].Err)
 		}
+		return msg
+	}
+}
 
-		lines := strings.Split(string(contentBytes), "\n")
+// grepLogs searches the log on the servers instead of downloading it,
+// showing only the lines matching the filter
+func (m LogSelectionModel) grepLogs(servers []string, logFilePath string, locate source.Locator, window viewer.TimeWindow) loadingMsg {
+	search := source.GrepOptions{Pattern: m.filterInput}
+	buffers := viewer.GrepBuffers(context.Background(), m.config, m.selectedApp, servers, logFilePath, locate, search, window)
+
+	loaded := 0
+	for i, buffer := range buffers {
+		// Ask about unknown host keys before showing anything
+		var unknown *ssh.UnknownHostError
+		if errors.As(buffer.Err, &unknown) && !m.rejectedHosts[unknown.Host] {
+			return loadingMsg{err: unknown}
+		}
 
-		return loadingMsg{
-			content: lines,
-			server:  server,
-			logFile: logFileName,
+		switch {
+		case errors.Is(buffer.Err, os.ErrNotExist) && locate == nil:
+			buffers[i].Err = fmt.Errorf("log file not found: %s", logFilePath)
+		case buffer.Err == nil:
+			loaded++
 		}
 	}
+
+	// With a single server there is nothing else to show
+	if loaded == 0 && len(buffers) == 1 {
+		return loadingMsg{err: buffers[0].Err}
+	}
+	return loadingMsg{buffers: buffers}
 }
 
 var (
@@ -275,6 +587,10 @@ func (m LogSelectionModel) View() string {
 		s.WriteString(m.renderServerSelect())
 	case "date":
 		s.WriteString(m.renderDateInput())
+	case "inventory":
+		s.WriteString(m.renderInventory())
+	case "hostkey":
+		s.WriteString(m.renderHostKeyPrompt())
 	}
 
 	// Message
@@ -285,8 +601,20 @@ func (m LogSelectionModel) View() string {
 
 	// Help
 	s.WriteString("\n\n")
-	if m.mode == "date" {
-		help := "Type date (YYYY-MM-DD) • Enter: View • Esc: Back"
+	if m.mode == "date" && m.selectedApp.NumericRotation() {
+		help := "↑/↓: Pick rotation • Type date (YYYY-MM-DD) • Ctrl+F: Filter • Enter: View • Esc: Back"
+		s.WriteString(logHelpStyle.Render(help))
+	} else if m.mode == "date" && !m.hasCalendar() {
+		help := "←/→/↑/↓: Change day • PgUp/PgDn: Month • Type a date or FROM..TO • Ctrl+F: Filter • Enter: View • Esc: Back"
+		s.WriteString(logHelpStyle.Render(help))
+	} else if m.mode == "date" {
+		help := "←/→/↑/↓: Pick day • PgUp/PgDn: Month • Type a date or FROM..TO • Tab: Available logs • Ctrl+F: Filter • Enter: View • Esc: Back"
+		s.WriteString(logHelpStyle.Render(help))
+	} else if m.mode == "inventory" {
+		help := "↑/↓: Navigate • Enter: View date • Esc: Back to date"
+		s.WriteString(logHelpStyle.Render(help))
+	} else if m.mode == "hostkey" {
+		help := "y: Trust host • n/Esc: Cancel"
 		s.WriteString(logHelpStyle.Render(help))
 	} else {
 		help := "↑/↓: Navigate • Enter: Select • Esc: Back"
@@ -305,7 +633,7 @@ func (m LogSelectionModel) renderAppSelect() string {
 
 	for i, app := range m.apps {
 		cursor := " "
-		line := fmt.Sprintf("📱 %s (%d servers)", app.Name, len(app.Servers))
+		line := fmt.Sprintf("📱 %s (%s)", app.Name, serversLabel(&app))
 
 		if i == m.cursor {
 			cursor = logCursorStyle.Render("▶")
@@ -359,17 +687,96 @@ func (m LogSelectionModel) renderDateInput() string {
 	content := fmt.Sprintf("Viewing logs for: %s\n",
 		logFocusedStyle.Render(m.selectedApp.Name))
 
-	serverName := "All Servers"
-	if m.serverIdx >= 0 && m.serverIdx < len(m.selectedApp.Servers) {
-		serverName = m.selectedApp.Servers[m.serverIdx]
+	serverName := m.servers[0]
+	if m.serverIdx >= 0 && m.serverIdx+1 < len(m.servers) {
+		serverName = m.servers[m.serverIdx+1]
 	}
 	content += fmt.Sprintf("Server: %s\n\n",
 		logServerTagStyle.Render(serverName))
 
-	content += "Enter date (YYYY-MM-DD):\n"
-	content += logFocusedStyle.Render(m.dateInput + "█")
-	content += "\n\n"
-	content += logBlurredStyle.Render("Press Enter to view logs")
+	if m.selectedApp.NumericRotation() {
+		content += m.renderGenerations()
+		content += "\nOr type a date or FROM..TO: "
+		content += m.renderInput(m.dateInput, !m.filterFocus)
+		if m.dateInput != "" && m.serverIdx < 0 {
+			content += "\n" + logBlurredStyle.Render("Each server opens its own rotation for the date")
+		}
+		content += m.renderFilter()
+		return logBlurredStyle.Render(content)
+	}
+
+	if m.hasCalendar() {
+		content += m.renderCalendar()
+		content += "\n"
+	}
+	content += "Date, or type one (yesterday, -2d, monday...) or a time window (FROM..TO):\n"
+	content += m.renderInput(m.dateInput, !m.filterFocus)
+	content += m.renderFilter()
 
 	return logBlurredStyle.Render(content)
 }
+
+// renderInput shows a text field, with a cursor if it has focus
+func (m LogSelectionModel) renderInput(value string, focus bool) string {
+	if !focus {
+		return logFocusedStyle.Render(value)
+	}
+	return logFocusedStyle.Render(value + "█")
+}
+
+// renderFilter shows the pattern searched for on the servers
+func (m LogSelectionModel) renderFilter() string {
+	content := "\n\nOnly lines matching (grep on the servers): "
+	if m.filterInput == "" && !m.filterFocus {
+		return content + logBlurredStyle.Render("Ctrl+F to filter")
+	}
+	return content + m.renderInput(m.filterInput, m.filterFocus)
+}
+
+// renderGenerations lists the numbered rotations of the log with the time
+// each was last written
+func (m LogSelectionModel) renderGenerations() string {
+	content := fmt.Sprintf("Rotated logs on %s:\n\n", logServerTagStyle.Render(m.genHost))
+
+	switch {
+	case m.listing:
+		return content + logStatsStyle.Render("⏳ Listing...") + "\n"
+	case m.genErr != nil:
+		return content + errorStyle.Render(fmt.Sprintf("Error: %v", m.genErr)) + "\n"
+	case len(m.generations) == 0:
+		return content + logBlurredStyle.Render("No logs found: "+m.selectedApp.LogPath) + "\n"
+	}
+
+	for i, gen := range m.generations {
+		cursor := " "
+		line := fmt.Sprintf("%-20s %s", gen.Name, gen.ModTime.In(m.selectedApp.Location(m.genHost)).Format("2006-01-02 15:04"))
+		if gen.Index == 0 {
+			line += " (current)"
+		}
+
+		if i == m.cursor {
+			cursor = logCursorStyle.Render("▶")
+			line = logFocusedStyle.Render(line)
+		} else {
+			line = logBlurredStyle.Render(line)
+		}
+		content += fmt.Sprintf("%s %s\n", cursor, line)
+	}
+	return content
+}
+
+func (m LogSelectionModel) renderHostKeyPrompt() string {
+	if m.unknownHost == nil {
+		return ""
+	}
+
+	content := warningStyle.Render("⚠️  Unknown host key") + "\n\n"
+	content += fmt.Sprintf("The authenticity of host %s can't be established.\n\n",
+		logServerTagStyle.Render(m.unknownHost.Host))
+	content += fmt.Sprintf("%s key fingerprint:\n", m.unknownHost.Key.Type())
+	content += logFocusedStyle.Render(ssh.Fingerprint(m.unknownHost.Key))
+	content += "\n\n"
+	content += "Trust this host and continue connecting? (y/n)"
+
+	return logMenuBoxStyle.Width(70).Render(content)
+}
@@ -3,15 +3,17 @@ package ui
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/ssh"
 	"github.com/jatsandaruwan/logx/internal/vault"
 	"golang.org/x/term"
 )
 
 // AddUserInteractive shows interactive UI for adding a user
 func AddUserInteractive() error {
-	// Read password from terminal
 	fmt.Print("User Name (identifier): ")
 	var name string
 	fmt.Scanln(&name)
@@ -20,13 +22,50 @@ func AddUserInteractive() error {
 	var username string
 	fmt.Scanln(&username)
 
-	fmt.Print("SSH Password: ")
-	passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
-	if err != nil {
-		return err
+	fmt.Printf("Auth methods in order (comma separated: agent,key,password) [%s]: ",
+		strings.Join(ssh.DefaultAuthMethods, ","))
+	var methodsInput string
+	fmt.Scanln(&methodsInput)
+	methods := splitList(methodsInput)
+	for _, method := range methods {
+		switch method {
+		case ssh.AuthAgent, ssh.AuthKey, ssh.AuthPassword:
+		default:
+			return fmt.Errorf("unknown auth method: %s", method)
+		}
+	}
+	effective := methods
+	if len(effective) == 0 {
+		effective = ssh.DefaultAuthMethods
+	}
+
+	var keyFiles []string
+	var passphrase string
+	if slices.Contains(effective, ssh.AuthKey) {
+		fmt.Print("Private key files (comma separated, empty for ~/.ssh defaults): ")
+		var keysInput string
+		fmt.Scanln(&keysInput)
+		keyFiles = splitList(keysInput)
+
+		fmt.Print("Key passphrase (empty if none): ")
+		passphraseBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		passphrase = string(passphraseBytes)
+		fmt.Println()
+	}
+
+	var password string
+	if slices.Contains(effective, ssh.AuthPassword) {
+		fmt.Print("SSH Password (empty to skip): ")
+		passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return err
+		}
+		password = string(passwordBytes)
+		fmt.Println()
 	}
-	password := string(passwordBytes)
-	fmt.Println()
 
 	// Save user
 	cfg, err := config.Load()
@@ -38,6 +77,8 @@ func AddUserInteractive() error {
 		ID:       name,
 		Name:     name,
 		Username: username,
+		Auth:     methods,
+		KeyFiles: keyFiles,
 	}
 
 	if err := cfg.AddUser(user); err != nil {
@@ -56,7 +97,23 @@ func AddUserInteractive() error {
 	if err := vault.Store(name, creds); err != nil {
 		return err
 	}
+	if passphrase != "" {
+		if err := vault.StorePassphrase(name, passphrase); err != nil {
+			return err
+		}
+	}
 
 	fmt.Printf("✓ User '%s' added successfully!\n", name)
 	return nil
 }
+
+// splitList splits a comma separated input into trimmed, non-empty values
+func splitList(input string) []string {
+	var result []string
+	for _, part := range strings.Split(input, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -6,7 +6,10 @@ import (
 	"github.com/zalando/go-keyring"
 )
 
-const serviceName = "logx"
+const (
+	serviceName      = "logx"
+	passphraseSuffix = ":passphrase"
+)
 
 // Credentials holds SSH credentials
 type Credentials struct {
@@ -55,9 +58,25 @@ func Get(userID string) (*Credentials, error) {
 
 // Delete removes credentials from system keyring
 func Delete(userID string) error {
+	// The key passphrase is optional, so a missing entry is not an error
+	_ = keyring.Delete(serviceName, userID+passphraseSuffix)
 	return keyring.Delete(serviceName, userID)
 }
 
+// StorePassphrase saves a private key passphrase to system keyring
+func StorePassphrase(userID, passphrase string) error {
+	return keyring.Set(serviceName, userID+passphraseSuffix, passphrase)
+}
+
+// GetPassphrase retrieves a private key passphrase from system keyring
+func GetPassphrase(userID string) (string, error) {
+	passphrase, err := keyring.Get(serviceName, userID+passphraseSuffix)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve key passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
 // Exists checks if credentials exist for a user
 func Exists(userID string) bool {
 	_, err := keyring.Get(serviceName, userID)
@@ -0,0 +1,303 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/logtime"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/transport"
+)
+
+var grepContextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+// ErrNoMatch is returned by a search that ran everywhere and found nothing
+var ErrNoMatch = errors.New("no matches found")
+
+// GrepOptions controls which log is searched on which servers
+type GrepOptions struct {
+	Server  string // Only search this server
+	Date    string // Day of the log, as for dates.Parse; the current log if empty
+	Current bool   // Search the current log even if Date is set
+	Since   string // Start of a time window to search instead of a date
+	Until   string // End of the time window; open if empty
+	Merge   bool   // Interleave results from several apps by timestamp
+	Search  source.GrepOptions
+}
+
+// grepTarget works out which log of an app to search, and the window to
+// keep lines from
+func grepTarget(app *config.App, servers []string, opts GrepOptions) (logTarget, TimeWindow, error) {
+	switch {
+	case opts.Since != "" || opts.Until != "":
+		w, err := ParseTimeWindow(opts.Since, opts.Until, app.Location(""))
+		if err != nil {
+			return logTarget{}, w, err
+		}
+		remotePath, locate := WindowLog(app, w)
+		return logTarget{path: remotePath, locate: locate}, w, nil
+
+	case opts.Date != "" && !opts.Current:
+		target, _, day, err := logForDate(app, servers, opts.Date)
+		if err == nil && app.Containers() {
+			// Keep kubectl, which can't stop at a time, to the day
+			return target, dayWindow(day), nil
+		}
+		return target, TimeWindow{}, err
+
+	default:
+		return logTarget{path: currentLog(app)}, TimeWindow{}, nil
+	}
+}
+
+// GrepLogs searches an app's log on its servers, running grep there so
+// that only matching lines and their context are transferred. Lines are
+// written to stdout as they arrive, tagged with their server and line
+// number, and a summary to stderr at the end. ErrNoMatch is returned if
+// every server was searched and nothing matched, and ctx's error if the
+// search was interrupted.
+func GrepLogs(ctx context.Context, appName string, opts GrepOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	app, err := cfg.GetApp(appName)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAccess(cfg, app); err != nil {
+		return err
+	}
+	if err := app.CheckTimezones(); err != nil {
+		return err
+	}
+
+	servers := []string{opts.Server}
+	if opts.Server == "" {
+		if servers, err = transport.HostsInteractive(cfg, app); err != nil {
+			return err
+		}
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("app '%s' has no servers", appName)
+	}
+
+	target, window, err := grepTarget(app, servers, opts)
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, server := range servers {
+		width = max(width, len(server))
+	}
+
+	stdout := &tailWriter{out: os.Stdout}
+	stderr := &tailWriter{out: os.Stderr}
+	notice := func(host string, err error) {
+		stderr.printf("%s\n", tailNoticeStyle.Render(fmt.Sprintf("%s: %v", host, err)))
+	}
+
+	// Connect one server at a time, so that unknown host keys can be
+	// confirmed before any output starts
+	clients := make([]source.Source, len(servers))
+	for i, host := range servers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		client, err := transport.OpenInteractive(cfg, app, host)
+		if err != nil {
+			if hostKeyError(err) {
+				return err
+			}
+			notice(host, err)
+			continue
+		}
+		defer client.Close()
+		clients[i] = client
+	}
+
+	matches := make([]int, len(servers))
+	searched := make([]bool, len(servers))
+	var wg sync.WaitGroup
+	for i, host := range servers {
+		if clients[i] == nil {
+			continue
+		}
+		prefix := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(hostColors[i%len(hostColors)])).
+			Bold(true).
+			Render(fmt.Sprintf("%-*s", width, host))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := grepServer(ctx, clients[i], host, target, opts.Search, window, app.Location(host),
+				func(file string, line source.GrepLine) {
+					if line.Match {
+						matches[i]++
+					}
+					text := formatGrepLine(file, line)
+					if !line.Match {
+						text = grepContextStyle.Render(text)
+					}
+					stdout.printf("%s │ %s\n", prefix, text)
+				})
+			if err != nil {
+				notice(host, err)
+				return
+			}
+			searched[i] = true
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	total, ok := 0, 0
+	for i := range servers {
+		total += matches[i]
+		if searched[i] {
+			ok++
+		}
+	}
+	stderr.printf("%s\n", tailNoticeStyle.Render(fmt.Sprintf("%d match(es) on %d of %d server(s)", total, ok, len(servers))))
+	return grepOutcome(total, len(servers)-ok)
+}
+
+// grepOutcome turns the totals of a search into its result: nil if
+// anything matched, ErrNoMatch if nothing did anywhere, and an error if
+// nothing matched but some servers could not be searched
+func grepOutcome(matches, failed int) error {
+	switch {
+	case matches > 0:
+		return nil
+	case failed > 0:
+		return fmt.Errorf("no matches found; %d server(s) could not be searched", failed)
+	default:
+		return ErrNoMatch
+	}
+}
+
+// GrepBuffers searches a log on each server at once, for the internal
+// viewer: one buffer per server holding the matching lines and their
+// context, tagged with line numbers. remotePath and locate find the files
+// as for transport.FetchAll; lines stamped outside w are left out.
+func GrepBuffers(ctx context.Context, cfg *config.Config, app *config.App, servers []string, remotePath string,
+	locate source.Locator, search source.GrepOptions, w TimeWindow) []LogBuffer {
+	target := logTarget{path: remotePath, locate: locate}
+	buffers := make([]LogBuffer, len(servers))
+
+	var wg sync.WaitGroup
+	for i, host := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buffer := LogBuffer{Server: host, Location: app.Location(host)}
+			defer func() { buffers[i] = buffer }()
+
+			client, err := transport.Open(cfg, app, host)
+			if err != nil {
+				buffer.Err = err
+				return
+			}
+			defer client.Close()
+
+			matches := 0
+			err = grepServer(ctx, client, host, target, search, w, buffer.Location,
+				func(file string, line source.GrepLine) {
+					if line.Match {
+						matches++
+					}
+					buffer.Content = append(buffer.Content, formatGrepLine(file, line))
+				})
+			if err != nil {
+				buffer.Err = err
+				return
+			}
+			buffer.LogFile = fmt.Sprintf("%d match(es) for %q", matches, search.Pattern)
+		}()
+	}
+	wg.Wait()
+
+	return buffers
+}
+
+// grepServer finds the files of a log on one server, as fetching would,
+// and greps each of them, oldest first. each gets the file name when the
+// log spans several files. Lines stamped outside w are dropped, along with
+// unstamped lines that follow them.
+func grepServer(ctx context.Context, client source.Source, host string, target logTarget, search source.GrepOptions,
+	w TimeWindow, loc *time.Location, each func(file string, line source.GrepLine)) error {
+	files, err := source.Locate(client, host, target.path, target.locate)
+	if err != nil {
+		return err
+	}
+
+	parser := logtime.NewParser(loc)
+	emitted, pending := false, false
+	for _, file := range files {
+		label := ""
+		if len(files) > 1 {
+			label = path.Base(file)
+		}
+
+		// Groups of lines, and files, are separated as grep does, once
+		// something follows them
+		pending = true
+		inside := true
+		err := client.Grep(ctx, file, search, func(line source.GrepLine) {
+			if line.Break {
+				// Whether the next group is inside w is unknown until
+				// a stamped line
+				pending, inside = true, true
+				return
+			}
+			if !w.IsZero() {
+				if t, ok := parser.Parse(line.Text); ok {
+					inside = !t.Before(w.Since) && (w.Until.IsZero() || !t.After(w.Until))
+				}
+				if !inside {
+					return
+				}
+			}
+
+			if pending && emitted {
+				each(label, source.GrepLine{Break: true})
+			}
+			pending, emitted = false, true
+			each(label, line)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatGrepLine shows a line found by grep the way grep -n does: "12:"
+// before a match, "12-" before context, with the file name in front when
+// a log spans several files
+func formatGrepLine(file string, line source.GrepLine) string {
+	if line.Break {
+		return "--"
+	}
+	sep := "-"
+	if line.Match {
+		sep = ":"
+	}
+	if file != "" {
+		return fmt.Sprintf("%s:%d%s %s", file, line.Number, sep, line.Text)
+	}
+	return fmt.Sprintf("%d%s %s", line.Number, sep, line.Text)
+}
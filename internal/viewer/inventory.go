@@ -0,0 +1,110 @@
+package viewer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/transport"
+)
+
+// maxMissingDays caps how many days without a log are named one by one
+const maxMissingDays = 10
+
+// ListLogs prints the dated logs of an app found on each server, so a date
+// that exists can be picked. Apps rotated by number list their numbered
+// rotations instead.
+func ListLogs(appName string, opts ViewOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	app, err := cfg.GetApp(appName)
+	if err != nil {
+		return err
+	}
+	if app.Containers() {
+		return fmt.Errorf("app %s reads logs with %s, which keeps no dated files to list", app.Name, app.Transport)
+	}
+	if app.NumericRotation() {
+		return ListGenerations(appName, opts)
+	}
+	if err := app.CheckTimezones(); err != nil {
+		return err
+	}
+
+	servers := app.Hosts()
+	if opts.Server != "" {
+		servers = []string{opts.Server}
+	}
+
+	for i, host := range servers {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", host)
+
+		client, err := transport.OpenInteractive(cfg, app, host)
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			continue
+		}
+		loc := app.Location(host)
+		logs, err := source.DatedLogs(client, app.LogPath, app.LogPattern, app.DateFormat, loc)
+		client.Close()
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			continue
+		}
+		if len(logs) == 0 {
+			fmt.Printf("  No dated logs found: %s\n", app.LogPattern)
+			continue
+		}
+
+		for _, log := range logs {
+			fmt.Printf("  %s  %-32s %s  %9s  %s\n", log.Date.Format("2006-01-02"), log.Name,
+				log.ModTime.In(loc).Format("2006-01-02 15:04"), FormatSize(log.Size),
+				strings.TrimPrefix(compress.Ext(log.Name), "."))
+		}
+		fmt.Printf("  %s\n", inventorySummary(logs))
+	}
+
+	return nil
+}
+
+// inventorySummary sums up the days covered by a server's dated logs and
+// names the days in between that have none
+func inventorySummary(logs []source.DatedLog) string {
+	first, last := logs[0].Date, logs[len(logs)-1].Date
+	summary := fmt.Sprintf("%d day(s), %s to %s", len(logs), first.Format("2006-01-02"), last.Format("2006-01-02"))
+
+	missing := MissingDays(logs)
+	switch {
+	case len(missing) == 0:
+		return summary
+	case len(missing) > maxMissingDays:
+		return fmt.Sprintf("%s; %d day(s) missing", summary, len(missing))
+	}
+
+	days := make([]string, len(missing))
+	for i, day := range missing {
+		days[i] = day.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s; missing %s", summary, strings.Join(days, ", "))
+}
+
+// MissingDays returns the days between the oldest and newest of a server's
+// dated logs that have no log, oldest first
+func MissingDays(logs []source.DatedLog) []time.Time {
+	var missing []time.Time
+	for i := 1; i < len(logs); i++ {
+		for day := logs[i-1].Date.AddDate(0, 0, 1); day.Before(logs[i].Date); day = day.AddDate(0, 0, 1) {
+			missing = append(missing, day)
+		}
+	}
+	return missing
+}
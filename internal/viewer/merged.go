@@ -0,0 +1,258 @@
+package viewer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jatsandaruwan/logx/internal/logtime"
+)
+
+// maxTagWidth caps the server column of the merged view
+const maxTagWidth = 20
+
+// mergedLine is a line of the merged view and the server it came from
+type mergedLine struct {
+	source int
+	text   string
+}
+
+// mergeLogs interleaves the lines of several logs in timestamp order. Lines
+// without a timestamp, such as stack traces, stay right after the line they
+// follow in their own log. Each log's timestamps are read in its own
+// location from locs.
+func mergeLogs(logs [][]string, locs []*time.Location) []mergedLine {
+	type cursor struct {
+		lines []string
+		times []time.Time
+		stamp []bool
+		next  int
+	}
+
+	total := 0
+	cursors := make([]cursor, len(logs))
+	for i, lines := range logs {
+		parser := logtime.NewParser(locs[i])
+		c := cursor{
+			lines: lines,
+			times: make([]time.Time, len(lines)),
+			stamp: make([]bool, len(lines)),
+		}
+		var last time.Time
+		for j, line := range lines {
+			if t, ok := parser.Parse(line); ok {
+				last = t
+				c.stamp[j] = true
+			}
+			c.times[j] = last
+		}
+		cursors[i] = c
+		total += len(lines)
+	}
+
+	merged := make([]mergedLine, 0, total)
+	prev := -1
+	for len(merged) < total {
+		pick := -1
+
+		// Keep continuation lines with the line before them
+		if prev >= 0 {
+			if c := cursors[prev]; c.next < len(c.lines) && !c.stamp[c.next] {
+				pick = prev
+			}
+		}
+
+		if pick < 0 {
+			for i, c := range cursors {
+				if c.next >= len(c.lines) {
+					continue
+				}
+				if pick < 0 || c.times[c.next].Before(cursors[pick].times[cursors[pick].next]) {
+					pick = i
+				}
+			}
+		}
+
+		c := &cursors[pick]
+		merged = append(merged, mergedLine{source: pick, text: c.lines[c.next]})
+		c.next++
+		prev = pick
+	}
+
+	return merged
+}
+
+// NewMergedLogViewer shows the logs of several servers as one, ordered by
+// timestamp, with a column naming the server of each line
+func NewMergedLogViewer(buffers []LogBuffer) LogViewerModel {
+	m := NewLogViewer(nil, "All servers (merged)", "")
+	m.sources = make([]string, len(buffers))
+	m.hidden = make([]bool, len(buffers))
+	for i, buffer := range buffers {
+		m.sources[i] = buffer.Server
+		if m.logFile == "" {
+			m.logFile = buffer.LogFile
+		}
+	}
+	locs := make([]*time.Location, len(buffers))
+	for i, buffer := range buffers {
+		locs[i] = buffer.location()
+	}
+	m.setMergedLines(mergeLogs(bufferContents(buffers), locs))
+	return m
+}
+
+// bufferContents returns the lines of each buffer, without the empty
+// string left after a final newline
+func bufferContents(buffers []LogBuffer) [][]string {
+	logs := make([][]string, len(buffers))
+	for i, buffer := range buffers {
+		lines := buffer.Content
+		if n := len(lines); n > 0 && lines[n-1] == "" {
+			lines = lines[:n-1]
+		}
+		logs[i] = lines
+	}
+	return logs
+}
+
+// setMergedLines replaces the merged lines, keeping the cursor at the
+// bottom if it was there
+func (m *LogViewerModel) setMergedLines(lines []mergedLine) {
+	atBottom := len(m.lineIndex) > 0 && m.cursor >= len(m.content)-1
+	m.merged = lines
+	m.applyFilter()
+	if atBottom {
+		m.cursor = len(m.content) - 1
+		m.ensureVisible()
+	}
+}
+
+// applyFilter rebuilds the visible lines from the merged ones, leaving out
+// hidden servers and keeping the cursor near the line it was on
+func (m *LogViewerModel) applyFilter() {
+	current := 0
+	if m.cursor < len(m.lineIndex) {
+		current = m.lineIndex[m.cursor]
+	}
+
+	m.content = make([]string, 0, len(m.merged))
+	m.lineIndex = make([]int, 0, len(m.merged))
+	m.cursor = -1
+	for i, line := range m.merged {
+		if m.hidden[line.source] {
+			continue
+		}
+		if m.cursor < 0 && i >= current {
+			m.cursor = len(m.content)
+		}
+		m.content = append(m.content, line.text)
+		m.lineIndex = append(m.lineIndex, i)
+	}
+
+	if len(m.content) == 0 {
+		m.content = append(m.content, "")
+		m.lineIndex = append(m.lineIndex, -1)
+	}
+	if m.cursor < 0 {
+		m.cursor = len(m.content) - 1
+	}
+	m.ensureVisible()
+
+	if m.searchQuery != "" {
+		m.performSearch()
+		m.searchIndex = 0
+	}
+}
+
+// handleMergedKey hides, shows and solos servers in the merged view. It
+// reports whether the key was one of its own.
+func (m *LogViewerModel) handleMergedKey(key string) bool {
+	solo := m.soloPending
+	m.soloPending = false
+
+	switch {
+	case key == "o":
+		m.soloPending = true
+		m.message = "Solo server: press its number"
+		return true
+
+	case key == "0":
+		for i := range m.hidden {
+			m.hidden[i] = false
+		}
+		m.message = "Showing all servers"
+
+	case len(key) == 1 && key[0] >= '1' && key[0] <= '9':
+		source := int(key[0] - '1')
+		if source >= len(m.sources) {
+			return true
+		}
+
+		if solo {
+			for i := range m.hidden {
+				m.hidden[i] = i != source
+			}
+			m.message = "Showing only " + m.sources[source]
+			break
+		}
+
+		if !m.hidden[source] && m.visibleSources() == 1 {
+			m.message = "At least one server must stay visible"
+			return true
+		}
+		m.hidden[source] = !m.hidden[source]
+		if m.hidden[source] {
+			m.message = "Hiding " + m.sources[source]
+		} else {
+			m.message = "Showing " + m.sources[source]
+		}
+
+	default:
+		return false
+	}
+
+	m.applyFilter()
+	return true
+}
+
+func (m LogViewerModel) visibleSources() int {
+	n := 0
+	for _, hidden := range m.hidden {
+		if !hidden {
+			n++
+		}
+	}
+	return n
+}
+
+// sourceTag renders the server column for a line of the merged view
+func (m LogViewerModel) sourceTag(line int) string {
+	width := 0
+	for _, source := range m.sources {
+		width = max(width, len(source))
+	}
+	width = min(width, maxTagWidth)
+
+	if line >= len(m.lineIndex) || m.lineIndex[line] < 0 {
+		return strings.Repeat(" ", width)
+	}
+
+	source := m.merged[m.lineIndex[line]].source
+	name := m.sources[source]
+	if len(name) > width {
+		name = name[:width-1] + "…"
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(hostColors[source%len(hostColors)])).
+		Render(fmt.Sprintf("%-*s", width, name))
+}
+
+// sourceName is the server of a line of the merged view, for saving
+func (m LogViewerModel) sourceName(line int) string {
+	if line >= len(m.lineIndex) || m.lineIndex[line] < 0 {
+		return ""
+	}
+	return m.sources[m.merged[m.lineIndex[line]].source]
+}
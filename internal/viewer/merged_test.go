@@ -0,0 +1,108 @@
+package viewer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeLogs(t *testing.T) {
+	utc := time.UTC
+	plus2 := time.FixedZone("UTC+2", 2*60*60)
+
+	type line struct {
+		source int
+		text   string
+	}
+	tests := []struct {
+		name string
+		logs [][]string
+		locs []*time.Location
+		want []line
+	}{
+		{
+			name: "interleaved by time",
+			logs: [][]string{
+				{"2024-03-09 10:00:00 a1", "2024-03-09 10:00:02 a2"},
+				{"2024-03-09 10:00:01 b1", "2024-03-09 10:00:03 b2"},
+			},
+			locs: []*time.Location{utc, utc},
+			want: []line{
+				{0, "2024-03-09 10:00:00 a1"},
+				{1, "2024-03-09 10:00:01 b1"},
+				{0, "2024-03-09 10:00:02 a2"},
+				{1, "2024-03-09 10:00:03 b2"},
+			},
+		},
+		{
+			name: "continuation lines stay with their line",
+			logs: [][]string{
+				{"2024-03-09 10:00:00 ERROR a", "\tat A.java:1", "\tat A.java:2", "2024-03-09 10:00:05 a2"},
+				{"2024-03-09 10:00:01 b1"},
+			},
+			locs: []*time.Location{utc, utc},
+			want: []line{
+				{0, "2024-03-09 10:00:00 ERROR a"},
+				{0, "\tat A.java:1"},
+				{0, "\tat A.java:2"},
+				{1, "2024-03-09 10:00:01 b1"},
+				{0, "2024-03-09 10:00:05 a2"},
+			},
+		},
+		{
+			name: "equal times keep the order of the logs",
+			logs: [][]string{
+				{"2024-03-09 10:00:00 a"},
+				{"2024-03-09 10:00:00 b"},
+			},
+			locs: []*time.Location{utc, utc},
+			want: []line{
+				{0, "2024-03-09 10:00:00 a"},
+				{1, "2024-03-09 10:00:00 b"},
+			},
+		},
+		{
+			name: "each log read in its own timezone",
+			logs: [][]string{
+				{"2024-03-09 10:30:00 utc"},
+				{"2024-03-09 12:00:00 plus two"}, // 10:00 UTC
+			},
+			locs: []*time.Location{utc, plus2},
+			want: []line{
+				{1, "2024-03-09 12:00:00 plus two"},
+				{0, "2024-03-09 10:30:00 utc"},
+			},
+		},
+		{
+			name: "lines before any timestamp come first",
+			logs: [][]string{
+				{"2024-03-09 10:00:00 a"},
+				{"banner", "2024-03-09 09:00:00 b"},
+			},
+			locs: []*time.Location{utc, utc},
+			want: []line{
+				{1, "banner"},
+				{1, "2024-03-09 09:00:00 b"},
+				{0, "2024-03-09 10:00:00 a"},
+			},
+		},
+		{
+			name: "empty log",
+			logs: [][]string{nil, {"2024-03-09 10:00:00 b"}},
+			locs: []*time.Location{utc, utc},
+			want: []line{{1, "2024-03-09 10:00:00 b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeLogs(tt.logs, tt.locs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("mergeLogs() returned %d lines %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, w := range tt.want {
+				if got[i].source != w.source || got[i].text != w.text {
+					t.Errorf("line %d = %d %q, want %d %q", i, got[i].source, got[i].text, w.source, w.text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package viewer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	tabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#666666")).
+			Padding(0, 1)
+
+	activeTabStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(lipgloss.Color("#7D56F4")).
+			Padding(0, 1)
+
+	errorTabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF0000")).
+			Padding(0, 1)
+
+	activeErrorTabStyle = activeTabStyle.
+				Background(lipgloss.Color("#FF0000"))
+)
+
+// tabBarHeight is how many lines the server tabs take above the log
+const tabBarHeight = 2
+
+// LogBuffer is one server's copy of a log
+type LogBuffer struct {
+	Server  string
+	LogFile string
+	Content []string
+	Follow  FollowFunc // nil when the log can't be followed
+	Err     error      // Why the log couldn't be loaded; shown instead of Content
+
+	// Location is the timezone of the log's timestamps; local time if nil
+	Location *time.Location
+
+	// Date is the day the log was asked for when comparing dates, so that
+	// synced panes line up the same time of day; zero otherwise
+	Date time.Time
+}
+
+// location returns the timezone to read the buffer's timestamps in
+func (b LogBuffer) location() *time.Location {
+	if b.Location == nil {
+		return time.Local
+	}
+	return b.Location
+}
+
+// MultiLogViewerModel shows a log from several servers, one buffer per
+// server, with a tab bar to switch between them. When more than one server
+// has the log, the first tab merges them all in time order.
+type MultiLogViewerModel struct {
+	buffers []LogBuffer
+	views   []LogViewerModel // The merged view first, if any, then one per buffer
+	active  int
+	width   int
+	height  int
+
+	hasMerged    bool
+	mergedCounts []int // Lines per buffer when the merged view was built
+
+	// Split layout: server buffers side by side, active is the focused one
+	split     bool
+	sync      string
+	timeCache map[int]paneTimes
+}
+
+func NewMultiLogViewer(buffers []LogBuffer) MultiLogViewerModel {
+	m := MultiLogViewerModel{buffers: buffers, width: 80, height: 24}
+
+	loaded := 0
+	for _, buffer := range buffers {
+		if buffer.Err == nil {
+			loaded++
+		}
+	}
+	if loaded > 1 {
+		m.hasMerged = true
+		m.views = append(m.views, NewMergedLogViewer(buffers))
+	}
+
+	for _, buffer := range buffers {
+		if buffer.Err != nil {
+			content := []string{"✗ Failed to load log from " + buffer.Server, ""}
+			content = append(content, strings.Split(buffer.Err.Error(), "\n")...)
+			content = append(content, "")
+			m.views = append(m.views, NewLogViewer(content, buffer.Server, buffer.LogFile))
+			continue
+		}
+		m.views = append(m.views, NewLogViewer(buffer.Content, buffer.Server, buffer.LogFile).WithFollow(buffer.Follow))
+	}
+
+	// Start on the merged view, or else the first server that loaded
+	if m.hasMerged {
+		m.mergedCounts = m.lineCounts()
+	} else {
+		for i, buffer := range buffers {
+			if buffer.Err == nil {
+				m.active = i
+				break
+			}
+		}
+	}
+
+	return m
+}
+
+// serverView is the index into views of a buffer's own viewer
+func (m MultiLogViewerModel) serverView(buffer int) int {
+	if m.hasMerged {
+		return buffer + 1
+	}
+	return buffer
+}
+
+// lineCounts returns how many lines each buffer's viewer holds
+func (m MultiLogViewerModel) lineCounts() []int {
+	counts := make([]int, len(m.buffers))
+	for i, buffer := range m.buffers {
+		if buffer.Err == nil {
+			counts[i] = len(m.views[m.serverView(i)].content)
+		}
+	}
+	return counts
+}
+
+// activate switches to a tab, bringing the merged view up to date with
+// lines followed in the server buffers since it was built
+func (m *MultiLogViewerModel) activate(view int) {
+	m.active = view
+	if !m.hasMerged || view != 0 {
+		return
+	}
+
+	counts := m.lineCounts()
+	if slices.Equal(counts, m.mergedCounts) {
+		return
+	}
+
+	logs := make([][]string, len(m.buffers))
+	locs := make([]*time.Location, len(m.buffers))
+	for i, buffer := range m.buffers {
+		if buffer.Err == nil {
+			logs[i] = m.views[m.serverView(i)].content
+		}
+		locs[i] = buffer.location()
+	}
+	m.views[0].setMergedLines(mergeLogs(logs, locs))
+	m.mergedCounts = counts
+}
+
+func (m MultiLogViewerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m MultiLogViewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resize()
+		return m, nil
+
+	case tea.KeyMsg:
+		key := msg.String()
+		if key == "ctrl+c" || key == "q" && !m.views[m.active].searchMode {
+			for i := range m.views {
+				m.views[i].stopFollowing()
+			}
+			return m, tea.Quit
+		}
+
+		searching := m.views[m.active].searchMode
+		if !searching && m.split {
+			switch key {
+			case "v":
+				m.toggleSplit()
+				return m, nil
+
+			case "tab":
+				m.focusPane(1)
+				return m, nil
+
+			case "shift+tab":
+				m.focusPane(-1)
+				return m, nil
+
+			case "=":
+				m.views[m.active].message = m.cycleSync()
+				return m, nil
+
+			case "m":
+				m.toggleSplit()
+				if m.hasMerged {
+					m.activate(0)
+				}
+				return m, nil
+
+			case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+				if i := int(key[0] - '1'); i < len(m.buffers) {
+					m.active = m.serverView(i)
+					m.resize()
+				}
+				return m, nil
+			}
+
+			cmd := m.updateView(m.active, msg)
+			m.syncPanes()
+			return m, cmd
+		}
+
+		if !searching {
+			switch key {
+			case "v":
+				m.toggleSplit()
+				return m, nil
+
+			case "tab":
+				m.activate((m.active + 1) % len(m.views))
+				return m, nil
+
+			case "shift+tab":
+				m.activate((m.active + len(m.views) - 1) % len(m.views))
+				return m, nil
+
+			case "m":
+				if m.hasMerged {
+					m.activate(0)
+				}
+				return m, nil
+
+			case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+				// In the merged view the numbers hide and show servers
+				if m.hasMerged && m.active == 0 {
+					break
+				}
+				if i := int(key[0] - '1'); i < len(m.buffers) {
+					m.activate(m.serverView(i))
+				}
+				return m, nil
+			}
+		}
+
+		cmd := m.updateView(m.active, msg)
+		if m.split && searching && key == "enter" {
+			m.searchAllPanes()
+			m.syncPanes()
+		}
+		return m, cmd
+
+	case followMsg, followDoneMsg:
+		// Only the buffer that started the follow session acts on these
+		var cmds []tea.Cmd
+		for i := range m.views {
+			cmds = append(cmds, m.updateView(i, msg))
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	return m, m.updateView(m.active, msg)
+}
+
+// updateView passes a message to one buffer's viewer
+func (m *MultiLogViewerModel) updateView(i int, msg tea.Msg) tea.Cmd {
+	model, cmd := m.views[i].Update(msg)
+	switch view := model.(type) {
+	case LogViewerModel:
+		m.views[i] = view
+	case *LogViewerModel:
+		m.views[i] = *view
+	}
+	return cmd
+}
+
+func (m MultiLogViewerModel) View() string {
+	var s strings.Builder
+	s.WriteString(m.renderTabs())
+	s.WriteString(strings.Repeat("\n", tabBarHeight))
+	if m.split {
+		s.WriteString(m.renderPanes())
+	} else {
+		s.WriteString(m.views[m.active].View())
+	}
+	return s.String()
+}
+
+// renderTabs draws one tab per server, scrolled so the active one is shown
+func (m MultiLogViewerModel) renderTabs() string {
+	var tabs []string
+	if m.hasMerged {
+		if m.active == 0 {
+			tabs = append(tabs, activeTabStyle.Render("⇅ Merged"))
+		} else {
+			tabs = append(tabs, tabStyle.Render("⇅ Merged"))
+		}
+	}
+
+	for i, buffer := range m.buffers {
+		view := m.serverView(i)
+		label := fmt.Sprintf("%d %s", i+1, buffer.Server)
+		if m.views[view].following {
+			label += " ●"
+		}
+		if m.hasMerged && m.views[0].hidden[i] {
+			label += " (hidden)"
+		}
+
+		switch {
+		case buffer.Err != nil && view == m.active:
+			tabs = append(tabs, activeErrorTabStyle.Render("✗ "+label))
+		case buffer.Err != nil:
+			tabs = append(tabs, errorTabStyle.Render("✗ "+label))
+		case view == m.active:
+			tabs = append(tabs, activeTabStyle.Render(label))
+		default:
+			tabs = append(tabs, tabStyle.Render(label))
+		}
+	}
+
+	hint := "  Tab: Next server"
+	if m.split {
+		hint = fmt.Sprintf("  Tab: Next pane | =: Sync (%s) | v: Single", syncName(m.sync))
+	} else if len(m.buffers) > 1 {
+		hint += " | v: Split"
+	}
+	if m.hasMerged {
+		hint += " | m: Merged"
+	}
+	hint = helpStyle.Render(hint)
+	room := m.width - lipgloss.Width(hint)
+
+	start := 0
+	for start < m.active && lipgloss.Width(strings.Join(tabs[start:m.active+1], "")) > room-1 {
+		start++
+	}
+
+	var bar strings.Builder
+	if start > 0 {
+		bar.WriteString(tabStyle.Render("…"))
+	}
+	for _, tab := range tabs[start:] {
+		if lipgloss.Width(bar.String()+tab) > room {
+			bar.WriteString(tabStyle.Render("…"))
+			break
+		}
+		bar.WriteString(tab)
+	}
+	bar.WriteString(hint)
+
+	return bar.String()
+}
+
+func syncName(sync string) string {
+	if sync == syncOff {
+		return "off"
+	}
+	return sync
+}
+
+// OpenMultiViewer opens logs from several servers in the internal viewer,
+// one switchable buffer per server
+func OpenMultiViewer(buffers []LogBuffer) error {
+	if len(buffers) == 1 && buffers[0].Err == nil {
+		b := buffers[0]
+		return OpenInternalViewer(b.Content, b.Server, b.LogFile, b.Follow)
+	}
+
+	return runMultiViewer(NewMultiLogViewer(buffers))
+}
+
+// OpenSplitViewer opens logs side by side, scrolling together by timestamp,
+// for comparing servers or dates
+func OpenSplitViewer(buffers []LogBuffer) error {
+	m := NewMultiLogViewer(buffers)
+	m.sync = syncTime
+	m.toggleSplit()
+	return runMultiViewer(m)
+}
+
+func runMultiViewer(m MultiLogViewerModel) error {
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error running viewer: %w", err)
+	}
+
+	return nil
+}
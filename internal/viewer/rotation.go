@@ -0,0 +1,176 @@
+package viewer
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/compress"
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/transport"
+)
+
+// logTarget is the log to fetch from each server
+type logTarget struct {
+	path   string         // Remote path; the live log when locate is set
+	locate source.Locator // Finds the files on each server when their names vary
+}
+
+// logForDate returns the log holding a day's lines on each server, a name
+// to show for it and the day in the app's timezone. A relative date such as
+// "today" is resolved on each server's clock, so servers in other timezones
+// may be on another day.
+func logForDate(app *config.App, servers []string, dateStr string) (logTarget, string, time.Time, error) {
+	logDate, err := parseDate(dateStr, app.Location(""))
+	if err != nil {
+		return logTarget{}, "", time.Time{}, err
+	}
+
+	byHost := make(map[string]time.Time)
+	sameDay := true
+	for _, host := range servers {
+		date, err := parseDate(dateStr, app.Location(host))
+		if err != nil {
+			return logTarget{}, "", time.Time{}, err
+		}
+		byHost[host] = date
+		if date.Format("2006-01-02") != logDate.Format("2006-01-02") {
+			sameDay = false
+		}
+	}
+	dateFor := func(host string) time.Time {
+		if date, ok := byHost[host]; ok {
+			return date
+		}
+		return logDate
+	}
+
+	if app.Containers() {
+		w := dayWindow(logDate)
+		return logTarget{path: source.LogSpan(w.Since, w.Until)}, "logs of " + logDate.Format("2006-01-02"), logDate, nil
+	}
+
+	if app.NumericRotation() {
+		name := fmt.Sprintf("%s (numbered rotations)", path.Base(app.LogPath))
+		locate := func(c source.Source, host string) ([]string, error) {
+			return source.LocateDate(app.LogPath, dateFor(host))(c, host)
+		}
+		return logTarget{path: app.LogPath, locate: locate}, name, logDate, nil
+	}
+
+	name, logPath := datedLogPath(app, logDate)
+	target := logTarget{path: logPath}
+	if !sameDay {
+		target.locate = func(c source.Source, host string) ([]string, error) {
+			_, hostPath := datedLogPath(app, dateFor(host))
+			found, err := source.FindLog(c, hostPath)
+			if err != nil {
+				return nil, err
+			}
+			return []string{found}, nil
+		}
+	}
+	return target, name, logDate, nil
+}
+
+// DateLog returns the path of an app's log for a date, typed as for
+// dates.Parse, and a Locator for when the file to fetch differs between
+// servers
+func DateLog(app *config.App, servers []string, dateStr string) (string, source.Locator, error) {
+	target, _, _, err := logForDate(app, servers, dateStr)
+	return target.path, target.locate, err
+}
+
+// CanFollow reports whether a log fetched from remotePath may still be
+// written to. Compressed files are finished, and a numbered rotation gets
+// renamed when the log rotates again.
+func CanFollow(app *config.App, remotePath string) bool {
+	if app.Containers() {
+		return source.OpenSpan(remotePath)
+	}
+	if compress.IsCompressed(remotePath) {
+		return false
+	}
+	return !app.NumericRotation() || remotePath == app.LogPath
+}
+
+// logForGeneration returns a numbered rotation of an app's log
+func logForGeneration(app *config.App, index int) (logTarget, string, error) {
+	if !app.NumericRotation() {
+		return logTarget{}, "", fmt.Errorf("app %s is not set up for numeric rotation", app.Name)
+	}
+	name := fmt.Sprintf("%s.%d", path.Base(app.LogPath), index)
+	return logTarget{path: app.LogPath, locate: source.LocateGeneration(app.LogPath, index)}, name, nil
+}
+
+// ListGenerations prints the numbered rotations of an app's log on each
+// server, with the time each was last written
+func ListGenerations(appName string, opts ViewOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	app, err := cfg.GetApp(appName)
+	if err != nil {
+		return err
+	}
+	if !app.NumericRotation() {
+		return fmt.Errorf("app %s is not set up for numeric rotation", app.Name)
+	}
+	if err := app.CheckTimezones(); err != nil {
+		return err
+	}
+
+	servers := []string{opts.Server}
+	if opts.Server == "" {
+		if servers, err = transport.HostsInteractive(cfg, app); err != nil {
+			return err
+		}
+	}
+
+	for i, host := range servers {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", host)
+
+		client, err := transport.OpenInteractive(cfg, app, host)
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			continue
+		}
+		gens, err := source.Generations(client, app.LogPath)
+		client.Close()
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			continue
+		}
+		if len(gens) == 0 {
+			fmt.Printf("  No logs found: %s\n", app.LogPath)
+			continue
+		}
+
+		for _, gen := range gens {
+			fmt.Printf("  %3d  %-24s %s  %9s\n", gen.Index, gen.Name,
+				gen.ModTime.In(app.Location(host)).Format("2006-01-02 15:04"), FormatSize(gen.Size))
+		}
+	}
+
+	return nil
+}
+
+// FormatSize shows a byte count in the largest unit that keeps it above one
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
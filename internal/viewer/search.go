@@ -0,0 +1,271 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/ssh"
+	"github.com/jatsandaruwan/logx/internal/transport"
+)
+
+var grepHeaderStyle = lipgloss.NewStyle().Bold(true)
+
+// grepFound is a line a search sent back, and the file it came from when
+// the log spans several
+type grepFound struct {
+	file string
+	line source.GrepLine
+}
+
+// grepHit is what a search found on one server of an app
+type grepHit struct {
+	app     *config.App
+	host    string
+	target  logTarget
+	window  TimeWindow
+	found   []grepFound
+	matches int
+	err     error
+}
+
+// tag names the app and server of a hit
+func (h *grepHit) tag() string {
+	return h.app.Name + "/" + h.host
+}
+
+// GrepApps searches the logs of several apps, or of every app if names is
+// empty, on all their servers at once. Results are printed per app and
+// server once all are in, or interleaved by timestamp if opts.Merge is
+// set, followed by the number of matches on each server. ErrNoMatch is
+// returned if everything was searched and nothing matched, and ctx's error
+// if the search was interrupted.
+func GrepApps(ctx context.Context, names []string, opts GrepOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	apps, err := grepApps(cfg, names)
+	if err != nil {
+		return err
+	}
+
+	var hits []*grepHit
+	for _, app := range apps {
+		if err := app.CheckTransport(); err != nil {
+			return err
+		}
+		if err := app.CheckTimezones(); err != nil {
+			return err
+		}
+
+		servers, err := transport.Hosts(cfg, app)
+		if err != nil {
+			return fmt.Errorf("app %s: %w", app.Name, err)
+		}
+		if opts.Server != "" {
+			if !slices.Contains(servers, opts.Server) {
+				continue
+			}
+			servers = []string{opts.Server}
+		}
+
+		target, window, err := grepTarget(app, servers, opts)
+		if err != nil {
+			return fmt.Errorf("app %s: %w", app.Name, err)
+		}
+		for _, host := range servers {
+			hits = append(hits, &grepHit{app: app, host: host, target: target, window: window})
+		}
+	}
+	if len(hits) == 0 {
+		return fmt.Errorf("no servers to search")
+	}
+
+	fetchOpts, err := transport.FetchOptionsFor(cfg)
+	if err != nil {
+		return err
+	}
+	if fetchOpts.Workers <= 0 {
+		fetchOpts.Workers = transport.DefaultFetchWorkers
+	}
+	if fetchOpts.Timeout <= 0 {
+		fetchOpts.Timeout = transport.DefaultFetchTimeout
+	}
+	fmt.Fprintf(os.Stderr, "Searching %d server(s) of %d app(s)...\n", len(hits), len(apps))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, fetchOpts.Workers)
+	for _, hit := range hits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				hit.run(ctx, cfg, opts.Search, fetchOpts.Timeout)
+			case <-ctx.Done():
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// Confirm unknown host keys one at a time and search those servers again
+	for _, hit := range hits {
+		hit.err = ssh.RetryTrustingHosts(hit.err, func() error {
+			hit.run(ctx, cfg, opts.Search, fetchOpts.Timeout)
+			return hit.err
+		})
+	}
+
+	if opts.Merge {
+		printMergedHits(hits)
+	} else {
+		printGroupedHits(hits)
+	}
+	return printGrepSummary(hits, len(apps))
+}
+
+// grepApps returns the apps with the given names, or every app
+func grepApps(cfg *config.Config, names []string) ([]*config.App, error) {
+	if len(names) == 0 {
+		if len(cfg.Apps.Apps) == 0 {
+			return nil, fmt.Errorf("no apps configured")
+		}
+		apps := make([]*config.App, len(cfg.Apps.Apps))
+		for i := range cfg.Apps.Apps {
+			apps[i] = &cfg.Apps.Apps[i]
+		}
+		return apps, nil
+	}
+
+	var apps []*config.App
+	for _, name := range names {
+		app, err := cfg.GetApp(name)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// run searches the hit's server, replacing anything found before
+func (h *grepHit) run(ctx context.Context, cfg *config.Config, search source.GrepOptions, timeout time.Duration) {
+	h.found, h.matches, h.err = nil, 0, nil
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := transport.Open(cfg, h.app, h.host)
+	if err != nil {
+		h.err = err
+		return
+	}
+	defer client.Close()
+
+	h.err = grepServer(ctx, client, h.host, h.target, search, h.window, h.app.Location(h.host),
+		func(file string, line source.GrepLine) {
+			if line.Match {
+				h.matches++
+			}
+			h.found = append(h.found, grepFound{file: file, line: line})
+		})
+	if errors.Is(h.err, context.DeadlineExceeded) {
+		h.err = fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// printGroupedHits prints the lines found on each server under a heading
+func printGroupedHits(hits []*grepHit) {
+	for _, hit := range hits {
+		if hit.matches == 0 {
+			continue
+		}
+		fmt.Println(grepHeaderStyle.Render(fmt.Sprintf("== %s (%d match(es))", hit.tag(), hit.matches)))
+		for _, f := range hit.found {
+			fmt.Println(grepText(f))
+		}
+		fmt.Println()
+	}
+}
+
+// printMergedHits prints the lines found everywhere as one log, ordered by
+// timestamp and tagged with their app and server
+func printMergedHits(hits []*grepHit) {
+	width := 0
+	logs := make([][]string, len(hits))
+	locs := make([]*time.Location, len(hits))
+	for i, hit := range hits {
+		width = max(width, len(hit.tag()))
+		for _, f := range hit.found {
+			logs[i] = append(logs[i], formatGrepLine(f.file, f.line))
+		}
+		locs[i] = hit.app.Location(hit.host)
+	}
+
+	// Lines of each server come out in their own order, so each one's next
+	// line is the one being printed
+	next := make([]int, len(hits))
+	for _, merged := range mergeLogs(logs, locs) {
+		hit := hits[merged.source]
+		f := hit.found[next[merged.source]]
+		next[merged.source]++
+
+		prefix := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(hostColors[merged.source%len(hostColors)])).
+			Bold(true).
+			Render(fmt.Sprintf("%-*s", width, hit.tag()))
+		fmt.Printf("%s │ %s\n", prefix, grepText(f))
+	}
+}
+
+// grepText formats a found line for the terminal, dimming context lines
+func grepText(f grepFound) string {
+	text := formatGrepLine(f.file, f.line)
+	if !f.line.Match {
+		return grepContextStyle.Render(text)
+	}
+	return text
+}
+
+// printGrepSummary prints the matches on each server and returns the
+// outcome of the search
+func printGrepSummary(hits []*grepHit, apps int) error {
+	width := 0
+	for _, hit := range hits {
+		width = max(width, len(hit.tag()))
+	}
+
+	total, failed := 0, 0
+	matchedApps := make(map[string]bool)
+	fmt.Fprintln(os.Stderr, "Summary:")
+	for _, hit := range hits {
+		switch {
+		case hit.err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "  %-*s  ✗ %v\n", width, hit.tag(), hit.err)
+		default:
+			total += hit.matches
+			if hit.matches > 0 {
+				matchedApps[hit.app.Name] = true
+			}
+			fmt.Fprintf(os.Stderr, "  %-*s  %d\n", width, hit.tag(), hit.matches)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d match(es) in %d of %d app(s); %d of %d server(s) searched\n",
+		total, len(matchedApps), apps, len(hits)-failed, len(hits))
+
+	return grepOutcome(total, failed)
+}
@@ -0,0 +1,293 @@
+package viewer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jatsandaruwan/logx/internal/logtime"
+)
+
+// minPaneWidth is the narrowest a split pane gets; with less room fewer
+// panes are shown at a time
+const minPaneWidth = 40
+
+// How split panes scroll together
+const (
+	syncOff  = ""
+	syncLine = "line" // Same line number in every pane
+	syncTime = "time" // Nearest timestamp in every pane
+)
+
+var paneSeparatorStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#7D56F4"))
+
+// paneTimes caches the timestamp of every line of a pane. Lines without one
+// take the timestamp of the line before.
+type paneTimes struct {
+	lines int
+	times []time.Time
+}
+
+// paneViews returns the views shown as panes: every server's own buffer
+func (m MultiLogViewerModel) paneViews() []int {
+	panes := make([]int, len(m.buffers))
+	for i := range m.buffers {
+		panes[i] = m.serverView(i)
+	}
+	return panes
+}
+
+// visiblePanes returns the panes that fit on screen, always including the
+// focused one
+func (m MultiLogViewerModel) visiblePanes() []int {
+	panes := m.paneViews()
+	fit := max(1, min(len(panes), m.width/minPaneWidth))
+
+	start := 0
+	for i, view := range panes {
+		if view == m.active {
+			start = max(0, min(i-fit/2, len(panes)-fit))
+			break
+		}
+	}
+	return panes[start : start+fit]
+}
+
+// paneWidth is the width of each visible pane, leaving room for separators
+func (m MultiLogViewerModel) paneWidth() int {
+	n := len(m.visiblePanes())
+	return max(1, (m.width-(n-1))/n)
+}
+
+// resize tells every view how much room it has in the current layout
+func (m *MultiLogViewerModel) resize() {
+	height := m.height - tabBarHeight
+	for i := range m.views {
+		width, viewHeight := m.width, height
+		if m.split && !(m.hasMerged && i == 0) {
+			// A view draws a line more than its height, and panes are
+			// cut to the height; keep their status and message lines
+			width, viewHeight = m.paneWidth(), height-1
+		}
+		m.updateView(i, tea.WindowSizeMsg{Width: width, Height: viewHeight})
+	}
+}
+
+// toggleSplit switches between one buffer at a time and panes side by side
+func (m *MultiLogViewerModel) toggleSplit() {
+	if len(m.buffers) < 2 {
+		return
+	}
+	m.split = !m.split
+	if m.split && m.hasMerged && m.active == 0 {
+		m.active = m.serverView(0)
+	}
+	m.resize()
+}
+
+// focusPane moves focus by delta panes, wrapping around
+func (m *MultiLogViewerModel) focusPane(delta int) {
+	panes := m.paneViews()
+	for i, view := range panes {
+		if view == m.active {
+			m.active = panes[(i+delta+len(panes))%len(panes)]
+			break
+		}
+	}
+	// The set of visible panes may have moved
+	m.resize()
+}
+
+// cycleSync steps through the ways panes scroll together
+func (m *MultiLogViewerModel) cycleSync() string {
+	switch m.sync {
+	case syncOff:
+		m.sync = syncLine
+		m.syncPanes()
+		return "Scrolling synced by line"
+	case syncLine:
+		m.sync = syncTime
+		m.syncPanes()
+		return "Scrolling synced by timestamp"
+	default:
+		m.sync = syncOff
+		return "Scrolling not synced"
+	}
+}
+
+// syncPanes moves every other pane to the line matching the focused one
+func (m *MultiLogViewerModel) syncPanes() {
+	if m.sync == syncOff {
+		return
+	}
+
+	src := m.views[m.active]
+	row := src.cursor - src.offset
+
+	for _, i := range m.paneViews() {
+		if i == m.active || m.isErrorView(i) {
+			continue
+		}
+		target := &m.views[i]
+
+		line := src.cursor
+		if m.sync == syncTime {
+			t, ok := m.lineTime(m.active, src.cursor)
+			if !ok {
+				continue
+			}
+			line = m.lineAtTime(i, t.AddDate(0, 0, m.dayShift(m.active, i)))
+		}
+
+		target.cursor = max(0, min(line, len(target.content)-1))
+		target.offset = max(0, target.cursor-row)
+		target.ensureVisible()
+	}
+}
+
+// isErrorView reports whether a view shows a fetch error rather than a log
+func (m MultiLogViewerModel) isErrorView(view int) bool {
+	for i, buffer := range m.buffers {
+		if m.serverView(i) == view {
+			return buffer.Err != nil
+		}
+	}
+	return false
+}
+
+// viewDate returns the day the log shown in a view was asked for when
+// comparing dates, or the zero time
+func (m MultiLogViewerModel) viewDate(view int) time.Time {
+	for i, buffer := range m.buffers {
+		if m.serverView(i) == view {
+			return buffer.Date
+		}
+	}
+	return time.Time{}
+}
+
+// viewLocation returns the timezone of the log shown in a view
+func (m MultiLogViewerModel) viewLocation(view int) *time.Location {
+	for i, buffer := range m.buffers {
+		if m.serverView(i) == view {
+			return buffer.location()
+		}
+	}
+	return time.Local
+}
+
+// times returns the cached timestamps of a view's lines, parsing them again
+// if lines have been added since
+func (m *MultiLogViewerModel) times(view int) paneTimes {
+	content := m.views[view].content
+	if cached, ok := m.timeCache[view]; ok && cached.lines == len(content) {
+		return cached
+	}
+
+	parser := logtime.NewParser(m.viewLocation(view))
+	pt := paneTimes{lines: len(content), times: make([]time.Time, len(content))}
+	var last time.Time
+	for i, line := range content {
+		if t, ok := parser.Parse(line); ok {
+			last = t
+		}
+		pt.times[i] = last
+	}
+
+	if m.timeCache == nil {
+		m.timeCache = make(map[int]paneTimes)
+	}
+	m.timeCache[view] = pt
+	return pt
+}
+
+// lineTime returns the timestamp that applies to a line of a view
+func (m *MultiLogViewerModel) lineTime(view, line int) (time.Time, bool) {
+	times := m.times(view).times
+	if line < 0 || line >= len(times) || times[line].IsZero() {
+		return time.Time{}, false
+	}
+	return times[line], true
+}
+
+// lineAtTime returns the first line of a view at or after t
+func (m *MultiLogViewerModel) lineAtTime(view int, t time.Time) int {
+	times := m.times(view).times
+	i := sort.Search(len(times), func(i int) bool {
+		return !times[i].IsZero() && !times[i].Before(t)
+	})
+	return min(i, len(times)-1)
+}
+
+// dayShift is how many days lie between the dates two views were asked
+// for, so that comparing yesterday with today lines up the same time of
+// day. Views of the same log on different servers are never shifted, even
+// when their clocks are on different days.
+func (m *MultiLogViewerModel) dayShift(from, to int) int {
+	a, b := m.viewDate(from), m.viewDate(to)
+	if a.IsZero() || b.IsZero() {
+		return 0
+	}
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dayB.Sub(dayA).Hours() / 24)
+}
+
+// searchAllPanes runs the focused pane's search in every other pane
+func (m *MultiLogViewerModel) searchAllPanes() {
+	query := m.views[m.active].searchQuery
+	total := len(m.views[m.active].searchResult)
+
+	for _, i := range m.paneViews() {
+		if i == m.active || m.isErrorView(i) {
+			continue
+		}
+		target := &m.views[i]
+		target.searchQuery = query
+		target.performSearch()
+		target.searchIndex = 0
+		total += len(target.searchResult)
+
+		if m.sync != syncOff || len(target.searchResult) == 0 {
+			target.message = fmt.Sprintf("Found %d matches", len(target.searchResult))
+			continue
+		}
+		// Not synced: jump to the first match at or after where the pane is
+		for j, line := range target.searchResult {
+			if line >= target.cursor {
+				target.searchIndex = j
+				break
+			}
+		}
+		target.cursor = target.searchResult[target.searchIndex]
+		target.ensureVisible()
+		target.message = fmt.Sprintf("Found %d matches", len(target.searchResult))
+	}
+
+	m.views[m.active].message = fmt.Sprintf("Found %d matches in this pane, %d in all panes",
+		len(m.views[m.active].searchResult), total)
+}
+
+// renderPanes draws the visible panes next to each other
+func (m MultiLogViewerModel) renderPanes() string {
+	panes := m.visiblePanes()
+	width := m.paneWidth()
+	height := m.height - tabBarHeight
+
+	separator := paneSeparatorStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", max(1, height)), "\n"))
+
+	var blocks []string
+	for i, view := range panes {
+		if i > 0 {
+			blocks = append(blocks, separator)
+		}
+		content := lipgloss.NewStyle().MaxWidth(width).MaxHeight(height).Render(m.views[view].View())
+		blocks = append(blocks, lipgloss.NewStyle().Width(width).Render(content))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, blocks...)
+}
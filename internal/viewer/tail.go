@@ -0,0 +1,283 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/ssh"
+	"github.com/jatsandaruwan/logx/internal/transport"
+)
+
+const (
+	// keepAliveInterval is how often an idle tail connection is probed
+	keepAliveInterval = 15 * time.Second
+
+	// Reconnect delays grow from minBackoff up to maxBackoff
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// hostColors are cycled through to tell servers apart in tail output
+var hostColors = []string{"#7D56F4", "#04B575", "#FFA500", "#00BFFF", "#FF69B4", "#ADFF2F", "#FF6347", "#BA55D3"}
+
+var tailNoticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+// tailWriter serializes lines from all servers onto one output
+type tailWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *tailWriter) printf(format string, args ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, format, args...)
+}
+
+// keepAliver is a source whose connection can be probed, as SSH clients can
+type keepAliver interface {
+	KeepAlive(ctx context.Context, interval time.Duration) error
+}
+
+// inoder is a source that can tell files apart by inode, so that a log
+// replaced while disconnected is noticed on reconnect
+type inoder interface {
+	Inode(path string) uint64
+}
+
+// tailSource is one server being followed
+type tailSource struct {
+	host   string
+	prefix string
+	client source.Source
+	offset int64  // -1 until the end of the file is known
+	inode  uint64 // Inode of the file offset belongs to, 0 if unknown
+}
+
+// TailLogs follows an app's current log file on all of its servers (or only
+// serverFilter) and writes new lines to stdout prefixed with the server they
+// came from. Lines not matching pattern are skipped when pattern is set.
+// Connection problems are reported on stderr and the server is reconnected
+// with backoff. It returns once ctx is cancelled.
+func TailLogs(ctx context.Context, appName, serverFilter, pattern string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	app, err := cfg.GetApp(appName)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAccess(cfg, app); err != nil {
+		return err
+	}
+
+	var grep *regexp.Regexp
+	if pattern != "" {
+		if grep, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	servers := []string{serverFilter}
+	if serverFilter == "" {
+		if servers, err = transport.HostsInteractive(cfg, app); err != nil {
+			return err
+		}
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("app '%s' has no servers", appName)
+	}
+
+	width := 0
+	for _, server := range servers {
+		width = max(width, len(server))
+	}
+
+	sources := make([]*tailSource, len(servers))
+	for i, server := range servers {
+		style := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(hostColors[i%len(hostColors)])).
+			Bold(true)
+		sources[i] = &tailSource{
+			host:   server,
+			prefix: style.Render(fmt.Sprintf("%-*s", width, server)),
+			offset: -1,
+		}
+	}
+
+	stdout := &tailWriter{out: os.Stdout}
+	stderr := &tailWriter{out: os.Stderr}
+
+	// Connect up front, one server at a time, so that unknown host keys can
+	// be confirmed before any output starts. Servers that can't be reached
+	// now are retried in the background.
+	for _, src := range sources {
+		if ctx.Err() != nil {
+			return nil
+		}
+		client, err := transport.OpenInteractive(cfg, app, src.host)
+		if err != nil {
+			if hostKeyError(err) {
+				return err
+			}
+			stderr.printf("%s\n", tailNoticeStyle.Render(fmt.Sprintf("%s: %v", src.host, err)))
+			continue
+		}
+		src.client = client
+	}
+
+	stderr.printf("%s\n", tailNoticeStyle.Render(fmt.Sprintf("Following %s on %d server(s), Ctrl+C to stop", currentLog(app), len(sources))))
+
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		wg.Add(1)
+		go func(src *tailSource) {
+			defer wg.Done()
+			src.run(ctx, cfg, app, grep, stdout, stderr)
+		}(src)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// run follows the app's log on one server until ctx is cancelled,
+// reconnecting whenever the connection drops
+func (src *tailSource) run(ctx context.Context, cfg *config.Config, app *config.App, grep *regexp.Regexp, stdout, stderr *tailWriter) {
+	notice := func(format string, args ...any) {
+		msg := fmt.Sprintf("%s: %s", src.host, fmt.Sprintf(format, args...))
+		stderr.printf("%s\n", tailNoticeStyle.Render(msg))
+	}
+
+	backoff := minBackoff
+	for {
+		if src.client == nil {
+			client, err := transport.Open(cfg, app, src.host)
+			if err != nil {
+				if hostKeyError(err) {
+					notice("%v", err)
+					return
+				}
+				notice("%v; retrying in %s", err, backoff)
+				if !sleep(ctx, backoff) {
+					return
+				}
+				backoff = min(backoff*2, maxBackoff)
+				continue
+			}
+			notice("reconnected")
+			src.client = client
+		}
+
+		delivered, err := src.follow(ctx, currentLog(app), grep, stdout, notice)
+		_ = src.client.Close()
+		src.client = nil
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Only a connection that worked for a while starts over with a
+		// short delay; one that keeps failing straight away backs off
+		if delivered {
+			backoff = minBackoff
+		}
+		notice("connection lost: %v; reconnecting in %s", err, backoff)
+		if !sleep(ctx, backoff) {
+			return
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// follow streams lines from the current connection until it fails,
+// reporting whether any were delivered before it did
+func (src *tailSource) follow(ctx context.Context, logPath string, grep *regexp.Regexp, stdout *tailWriter, notice func(string, ...any)) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start at the end of the file the first time, like tail -f without
+	// history; after a reconnect carry on where the last connection stopped
+	if src.offset < 0 {
+		info, err := src.client.Stat(logPath)
+		switch {
+		case err == nil:
+			src.offset = info.Size
+			if ids, ok := src.client.(inoder); ok {
+				src.inode = ids.Inode(logPath)
+			}
+		case errors.Is(err, os.ErrNotExist):
+			notice("%s does not exist yet, waiting for it", logPath)
+			src.offset = 0
+		default:
+			return false, err
+		}
+	}
+
+	// Probe connections that can go stale; local files have none
+	keepAlive := make(chan error, 1)
+	if conn, ok := src.client.(keepAliver); ok {
+		go func() { keepAlive <- conn.KeepAlive(ctx, keepAliveInterval) }()
+	}
+
+	events := make(chan source.FollowEvent)
+	done := make(chan error, 1)
+	go func() { done <- src.client.Follow(ctx, logPath, src.offset, src.inode, events) }()
+
+	delivered := false
+	for {
+		select {
+		case event := <-events:
+			if event.Rotated {
+				notice("%s was rotated, following the new file", logPath)
+			}
+			for _, line := range event.Lines {
+				if grep == nil || grep.MatchString(line) {
+					stdout.printf("%s │ %s\n", src.prefix, line)
+				}
+			}
+			src.offset = event.Offset
+			src.inode = event.Inode
+			delivered = true
+
+		case err := <-keepAlive:
+			cancel()
+			<-done
+			return delivered, err
+
+		case err := <-done:
+			return delivered, err
+		}
+	}
+}
+
+// hostKeyError reports whether err is a host key problem, which retrying
+// will not fix
+func hostKeyError(err error) bool {
+	var unknown *ssh.UnknownHostError
+	var changed *ssh.HostKeyChangedError
+	return errors.As(err, &unknown) || errors.As(err, &changed)
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
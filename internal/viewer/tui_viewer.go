@@ -1,12 +1,16 @@
 package viewer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jatsandaruwan/logx/internal/source"
 )
 
 var (
@@ -40,6 +44,14 @@ var (
 			Italic(true)
 )
 
+// lastFollowGen numbers follow sessions across all viewers, so that several
+// viewers in one program can tell their messages apart
+var lastFollowGen atomic.Int64
+
+// FollowFunc streams lines appended to the viewed log into events until ctx
+// is cancelled or the stream fails
+type FollowFunc func(ctx context.Context, events chan<- source.FollowEvent) error
+
 type LogViewerModel struct {
 	content      []string
 	serverName   string
@@ -53,10 +65,44 @@ type LogViewerModel struct {
 	searchResult []int
 	searchIndex  int
 	message      string
+
+	// Follow mode
+	follow      FollowFunc
+	following   bool
+	partialTail bool // The last line has no newline yet and may be continued
+	followGen   int  // Tells messages of an earlier follow session apart
+	followCh    followChannels
+	stopFollow  context.CancelFunc
+
+	// Merged view of several servers
+	sources     []string     // Servers, numbered from 1 for the toggle keys
+	merged      []mergedLine // Lines of all servers, including hidden ones
+	lineIndex   []int        // Index into merged of each line of content
+	hidden      []bool       // Servers left out of content
+	soloPending bool         // o was pressed; the next number picks the server
+}
+
+// followChannels connect the viewer to a running FollowFunc
+type followChannels struct {
+	gen    int
+	events chan source.FollowEvent
+	done   chan error
+}
+
+// followMsg carries new lines from the followed log
+type followMsg struct {
+	gen   int
+	event source.FollowEvent
+}
+
+// followDoneMsg reports that the follow stream has ended
+type followDoneMsg struct {
+	gen int
+	err error
 }
 
 func NewLogViewer(content []string, serverName, logFile string) LogViewerModel {
-	return LogViewerModel{
+	m := LogViewerModel{
 		content:    content,
 		serverName: serverName,
 		logFile:    logFile,
@@ -65,6 +111,27 @@ func NewLogViewer(content []string, serverName, logFile string) LogViewerModel {
 		offset:     0,
 		cursor:     0,
 	}
+
+	// Content split on newlines ends in "" when the file ended with one;
+	// otherwise the last line may still be written to
+	if n := len(content); n > 0 {
+		if content[n-1] == "" {
+			m.content = content[:n-1]
+		} else {
+			m.partialTail = true
+		}
+	}
+	if len(m.content) == 0 {
+		m.content = []string{""}
+	}
+
+	return m
+}
+
+// WithFollow enables follow mode (f key) using the given stream
+func (m LogViewerModel) WithFollow(follow FollowFunc) LogViewerModel {
+	m.follow = follow
+	return m
 }
 
 func (m LogViewerModel) Init() tea.Cmd {
@@ -83,10 +150,28 @@ func (m LogViewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleSearchInput(msg)
 		}
 
+		if m.sources != nil && m.handleMergedKey(msg.String()) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
+			m.stopFollowing()
 			return m, tea.Quit
 
+		case "f":
+			if m.follow == nil {
+				m.message = "Follow mode is not available for this log"
+				return m, nil
+			}
+			if m.following {
+				m.stopFollowing()
+				m.message = "Follow mode off"
+				return m, nil
+			}
+			m.message = ""
+			return m, m.startFollowing()
+
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -159,11 +244,103 @@ func (m LogViewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "s":
 			return m, m.saveLog()
 		}
+
+	case followMsg:
+		if msg.gen != m.followGen || !m.following {
+			return m, nil
+		}
+		m.appendLines(msg.event)
+		return m, waitForFollow(m.followCh)
+
+	case followDoneMsg:
+		if msg.gen == m.followGen && m.following {
+			m.following = false
+			if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+				m.message = fmt.Sprintf("Follow stopped: %v", msg.err)
+			}
+		}
 	}
 
 	return m, nil
 }
 
+// startFollowing begins streaming new lines in the background
+func (m *LogViewerModel) startFollowing() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.followGen = int(lastFollowGen.Add(1))
+	m.following = true
+	m.stopFollow = cancel
+	m.followCh = followChannels{
+		gen: m.followGen,
+		// Unbuffered, so every batch is delivered before done fires
+		events: make(chan source.FollowEvent),
+		done:   make(chan error, 1),
+	}
+
+	follow, ch := m.follow, m.followCh
+	go func() {
+		ch.done <- follow(ctx, ch.events)
+	}()
+
+	// Start at the bottom so new lines scroll into view
+	m.cursor = len(m.content) - 1
+	m.ensureVisible()
+
+	return waitForFollow(ch)
+}
+
+func (m *LogViewerModel) stopFollowing() {
+	if m.stopFollow != nil {
+		m.stopFollow()
+		m.stopFollow = nil
+	}
+	m.following = false
+}
+
+// waitForFollow delivers the next batch of followed lines as a message
+func waitForFollow(ch followChannels) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case event := <-ch.events:
+			return followMsg{gen: ch.gen, event: event}
+		case err := <-ch.done:
+			return followDoneMsg{gen: ch.gen, err: err}
+		}
+	}
+}
+
+// appendLines adds followed lines, auto-scrolling only when the cursor is on
+// the last line so that scrolling up pauses the view
+func (m *LogViewerModel) appendLines(event source.FollowEvent) {
+	atBottom := m.cursor >= len(m.content)-1
+
+	if event.Rotated {
+		m.partialTail = false
+		m.message = "Log rotated, following the new file"
+	}
+
+	lines := event.Lines
+	if m.partialTail && len(lines) > 0 {
+		m.content[len(m.content)-1] += lines[0]
+		lines = lines[1:]
+		m.partialTail = false
+	}
+	if len(m.content) == 1 && m.content[0] == "" && len(lines) > 0 {
+		m.content = m.content[:0]
+	}
+	m.content = append(m.content, lines...)
+
+	if m.searchQuery != "" {
+		m.performSearch()
+	}
+
+	if atBottom {
+		m.cursor = len(m.content) - 1
+		m.ensureVisible()
+	}
+}
+
 func (m *LogViewerModel) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -225,7 +402,14 @@ func (m *LogViewerModel) ensureVisible() {
 func (m LogViewerModel) saveLog() tea.Cmd {
 	return func() tea.Msg {
 		filename := fmt.Sprintf("%s_%s.log", m.serverName, strings.ReplaceAll(m.logFile, "/", "_"))
-		content := strings.Join(m.content, "\n")
+		lines := m.content
+		if m.sources != nil {
+			lines = make([]string, len(m.content))
+			for i, line := range m.content {
+				lines[i] = fmt.Sprintf("[%s] %s", m.sourceName(i), line)
+			}
+		}
+		content := strings.Join(lines, "\n")
 
 		err := os.WriteFile(filename, []byte(content), 0644)
 		if err != nil {
@@ -276,6 +460,10 @@ func (m LogViewerModel) View() string {
 
 		s.WriteString(lineNum)
 		s.WriteString(" ")
+		if m.sources != nil {
+			s.WriteString(m.sourceTag(i))
+			s.WriteString(" ")
+		}
 		s.WriteString(contentStyle.Render(line))
 		s.WriteString("\n")
 	}
@@ -286,6 +474,16 @@ func (m LogViewerModel) View() string {
 	if len(m.searchResult) > 0 {
 		status += fmt.Sprintf("| Match %d/%d ", m.searchIndex+1, len(m.searchResult))
 	}
+	if m.sources != nil {
+		status += fmt.Sprintf("| Servers %d/%d ", m.visibleSources(), len(m.sources))
+	}
+	if m.following {
+		if m.cursor >= len(m.content)-1 {
+			status += "| FOLLOWING "
+		} else {
+			status += "| FOLLOW PAUSED (G to resume) "
+		}
+	}
 	s.WriteString(statusStyle.Render(status))
 
 	// Help bar or message
@@ -294,6 +492,12 @@ func (m LogViewerModel) View() string {
 		s.WriteString(helpStyle.Render(m.message))
 	} else {
 		help := "↑↓: Navigate | /: Search | n/N: Next/Prev | s: Save | q: Quit"
+		if m.follow != nil {
+			help = "↑↓: Navigate | /: Search | n/N: Next/Prev | f: Follow | s: Save | q: Quit"
+		}
+		if m.sources != nil {
+			help = "↑↓: Navigate | /: Search | 1-9: Hide/show server | o+N: Solo | 0: All | s: Save | q: Quit"
+		}
 		s.WriteString(helpStyle.Render(help))
 	}
 
@@ -316,9 +520,10 @@ func highlightSearch(line, query string) string {
 	return before + searchStyle.Render(match) + after
 }
 
-// OpenInternalViewer opens the log in the internal TUI viewer
-func OpenInternalViewer(content []string, serverName, logFile string) error {
-	m := NewLogViewer(content, serverName, logFile)
+// OpenInternalViewer opens the log in the internal TUI viewer. follow may be
+// nil when the log cannot be followed.
+func OpenInternalViewer(content []string, serverName, logFile string, follow FollowFunc) error {
+	m := NewLogViewer(content, serverName, logFile).WithFollow(follow)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
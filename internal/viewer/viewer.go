@@ -1,19 +1,46 @@
 package viewer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/dates"
 	"github.com/jatsandaruwan/logx/internal/editor"
+	"github.com/jatsandaruwan/logx/internal/source"
 	"github.com/jatsandaruwan/logx/internal/ssh"
-	"github.com/jatsandaruwan/logx/internal/vault"
+	"github.com/jatsandaruwan/logx/internal/transport"
 )
 
+// Ways of opening downloaded logs
+const (
+	OpenEditor   = "editor"   // External editor (the default)
+	OpenInternal = "internal" // Built-in TUI viewer
+)
+
+// ViewOptions controls which servers logs are fetched from and how they
+// are opened
+type ViewOptions struct {
+	Server   string        // Only fetch from this server
+	OpenWith string        // OpenEditor or OpenInternal
+	Workers  int           // Servers fetched in parallel; from the config if zero
+	Timeout  time.Duration // Per-server limit; from the config if zero
+	Compare  string        // Another date (YYYY-MM-DD) to show side by side
+	Index    int           // Numbered rotation to open instead of a date; 0 if unset
+	Since    string        // Start of a time window to show instead of a date
+	Until    string        // End of the time window; open if empty
+
+	window TimeWindow // Since and Until, read in the app's timezone
+}
+
 // ViewLogs opens log files for the specified app and date
-func ViewLogs(appName, dateStr, serverFilter string) error {
+func ViewLogs(appName, dateStr string, opts ViewOptions) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -24,98 +51,199 @@ func ViewLogs(appName, dateStr, serverFilter string) error {
 		return err
 	}
 
-	// Get user credentials
-	user, err := cfg.GetUser(app.UserRef)
-	if err != nil {
+	// Make sure the app can be reached before connecting anywhere
+	if err := checkAccess(cfg, app); err != nil {
 		return err
 	}
 
-	creds, err := vault.Get(user.ID)
-	if err != nil {
-		return fmt.Errorf("failed to get credentials for user %s: %w", user.Name, err)
+	if err := app.CheckTimezones(); err != nil {
+		return err
 	}
 
-	// Parse date
-	var logDate time.Time
-	if dateStr == "" {
-		logDate = time.Now()
-	} else {
-		logDate, err = time.Parse("2006-01-02", dateStr)
+	// Filter servers if specified
+	servers := []string{opts.Server}
+	if opts.Server == "" {
+		if servers, err = transport.HostsInteractive(cfg, app); err != nil {
+			return err
+		}
+	}
+
+	if opts.Since != "" || opts.Until != "" {
+		opts.window, err = ParseTimeWindow(opts.Since, opts.Until, app.Location(""))
 		if err != nil {
-			return fmt.Errorf("invalid date format. Use YYYY-MM-DD: %w", err)
+			return err
 		}
+		remotePath, locate := WindowLog(app, opts.window)
+		fmt.Printf("Looking for logs: %s\n", path.Base(currentLog(app)))
+		fmt.Printf("Window: %s\n\n", opts.window)
+		return viewRemoteLog(cfg, app, servers, logTarget{path: remotePath, locate: locate}, opts,
+			fmt.Errorf("no log files found for the time window"))
 	}
 
-	// Format the log filename
-	formattedDate := logDate.Format(app.DateFormat)
-	logFileName := strings.ReplaceAll(app.LogPattern, "{date}", formattedDate)
-	logDir := filepath.Dir(app.LogPath)
-	logFilePath := filepath.Join(logDir, logFileName)
+	if opts.Index > 0 {
+		target, name, err := logForGeneration(app, opts.Index)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Looking for logs: %s\n\n", name)
+		return viewRemoteLog(cfg, app, servers, target, opts,
+			fmt.Errorf("no log files found for rotation %d", opts.Index))
+	}
+
+	target, logFileName, logDate, err := logForDate(app, servers, dateStr)
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Looking for logs: %s\n", logFileName)
 	fmt.Printf("Date: %s\n\n", logDate.Format("2006-01-02"))
 
-	// Filter servers if specified
-	servers := app.Servers
-	if serverFilter != "" {
-		servers = []string{serverFilter}
+	if app.Containers() && opts.Compare == "" {
+		// The day's span of a container log may run past the day when the
+		// CLI can't stop at a time, as kubectl can't
+		opts.window = dayWindow(logDate)
 	}
 
-	var downloadedFiles []string
-
-	// Connect to each server and download logs
-	for _, server := range servers {
-		fmt.Printf("Connecting to %s...\n", server)
-
-		client, err := ssh.Connect(server, creds.Username, creds.Password)
+	if opts.Compare != "" {
+		other, _, otherDate, err := logForDate(app, servers, opts.Compare)
 		if err != nil {
-			fmt.Printf("  ✗ Failed to connect: %v\n", err)
-			continue
+			return err
 		}
+		fmt.Printf("Comparing with: %s\n\n", otherDate.Format("2006-01-02"))
 
-		// Check if file exists
-		exists, err := client.FileExists(logFilePath)
-		if err != nil {
-			fmt.Printf("  ✗ Error checking file: %v\n", err)
-			client.Close()
-			continue
-		}
+		return compareRemoteLogs(cfg, app, servers,
+			[]logTarget{target, other}, []time.Time{logDate, otherDate}, opts)
+	}
 
-		if !exists {
-			fmt.Printf("  ✗ Log file not found: %s\n", logFilePath)
-			client.Close()
-			continue
+	return viewRemoteLog(cfg, app, servers, target, opts,
+		fmt.Errorf("no log files found for the specified date"))
+}
+
+// ViewCurrentLogs opens the current (non-dated) log file
+func ViewCurrentLogs(appName string, opts ViewOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	app, err := cfg.GetApp(appName)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the app can be reached before connecting anywhere
+	if err := checkAccess(cfg, app); err != nil {
+		return err
+	}
+
+	if err := app.CheckTimezones(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Looking for current logs: %s\n\n", currentLog(app))
+
+	// Filter servers if specified
+	servers := []string{opts.Server}
+	if opts.Server == "" {
+		if servers, err = transport.HostsInteractive(cfg, app); err != nil {
+			return err
 		}
+	}
 
-		// Download file
-		fmt.Printf("  ↓ Downloading log file...\n")
-		localPath, err := client.DownloadFile(logFilePath)
-		if err != nil {
-			fmt.Printf("  ✗ Failed to download: %v\n", err)
-			client.Close()
-			continue
+	return viewRemoteLog(cfg, app, servers, logTarget{path: currentLog(app)}, opts, fmt.Errorf("no log files found"))
+}
+
+// currentLog returns the path of an app's live log, which for docker and
+// kubectl apps is the whole log of each container
+func currentLog(app *config.App) string {
+	if app.Containers() {
+		return source.LogSpan(time.Time{}, time.Time{})
+	}
+	return app.LogPath
+}
+
+// checkAccess reports an app whose logs can't be reached: an unknown
+// transport, or a missing user to log in to its servers as
+func checkAccess(cfg *config.Config, app *config.App) error {
+	if err := app.CheckTransport(); err != nil {
+		return err
+	}
+	if !app.UsesSSH() {
+		return nil
+	}
+	_, err := cfg.GetUser(app.UserRef)
+	return err
+}
+
+// parseDate reads a date such as 2025-10-04, yesterday or -2d (see
+// dates.Parse), defaulting to today, on a clock in loc
+func parseDate(dateStr string, loc *time.Location) (time.Time, error) {
+	now := time.Now().In(loc)
+	if dateStr == "" {
+		return now, nil
+	}
+	return dates.Parse(dateStr, now)
+}
+
+// datedLogPath returns the name and full path of an app's log for a date
+func datedLogPath(app *config.App, logDate time.Time) (string, string) {
+	formattedDate := logDate.Format(app.DateFormat)
+	logFileName := strings.ReplaceAll(app.LogPattern, "{date}", formattedDate)
+	logDir := filepath.Dir(app.LogPath)
+	return logFileName, filepath.Join(logDir, logFileName)
+}
+
+// viewRemoteLog downloads a log from the servers in parallel and opens the
+// copies. notFound is returned when no server has the file.
+func viewRemoteLog(cfg *config.Config, app *config.App, servers []string, target logTarget, opts ViewOptions, notFound error) error {
+	results, err := fetchLogs(cfg, app, servers, target, opts)
+	defer func() {
+		for _, result := range results {
+			if result.Source != nil {
+				result.Source.Close()
+			}
 		}
+	}()
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("  ✓ Downloaded to: %s\n", localPath)
-		downloadedFiles = append(downloadedFiles, localPath)
+	var downloaded []transport.FetchResult
+	for _, result := range results {
+		if result.Err == nil {
+			downloaded = append(downloaded, result)
+		}
+	}
+	fmt.Printf("\nFetched %d of %d server(s)\n", len(downloaded), len(results))
 
-		client.Close()
+	if len(downloaded) == 0 {
+		return notFound
 	}
 
-	if len(downloadedFiles) == 0 {
-		return fmt.Errorf("no log files found for the specified date")
+	if opts.OpenWith == OpenInternal {
+		buffers, err := logBuffers(app, results, opts.window)
+		if err != nil {
+			return err
+		}
+		return OpenMultiViewer(buffers)
 	}
 
 	// Open each file in editor
 	fmt.Println("\nOpening log files...")
-	for _, file := range downloadedFiles {
+	for _, log := range downloaded {
+		localPath, err := viewFile(log, opts.window, app.Location(log.Host))
+		if err != nil {
+			fmt.Printf("Failed to open logs from %s: %v\n", log.Host, err)
+			continue
+		}
+
 		if cfg.Editor != "" {
-			if err := editor.OpenWithCustom(file, cfg.Editor); err != nil {
-				fmt.Printf("Failed to open %s: %v\n", file, err)
+			if err := editor.OpenWithCustom(localPath, cfg.Editor); err != nil {
+				fmt.Printf("Failed to open %s: %v\n", localPath, err)
 			}
 		} else {
-			if err := editor.Open(file); err != nil {
-				fmt.Printf("Failed to open %s: %v\n", file, err)
+			if err := editor.Open(localPath); err != nil {
+				fmt.Printf("Failed to open %s: %v\n", localPath, err)
 			}
 		}
 	}
@@ -123,95 +251,152 @@ func ViewLogs(appName, dateStr, serverFilter string) error {
 	return nil
 }
 
-// ViewCurrentLogs opens the current (non-dated) log file
-func ViewCurrentLogs(appName, serverFilter string) error {
-	cfg, err := config.Load()
+// fetchLogs downloads a log from every server at once, printing each
+// outcome as it arrives. Servers with unknown host keys are then confirmed
+// one by one and fetched again.
+func fetchLogs(cfg *config.Config, app *config.App, servers []string, target logTarget, opts ViewOptions) ([]transport.FetchResult, error) {
+	fetchOpts, err := transport.FetchOptionsFor(cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	app, err := cfg.GetApp(appName)
-	if err != nil {
-		return err
+	if opts.Workers > 0 {
+		fetchOpts.Workers = opts.Workers
 	}
-
-	// Get user credentials
-	user, err := cfg.GetUser(app.UserRef)
-	if err != nil {
-		return err
+	if opts.Timeout > 0 {
+		fetchOpts.Timeout = opts.Timeout
+	}
+	// The internal viewer follows files over the connection they came from
+	fetchOpts.KeepOpen = opts.OpenWith == OpenInternal
+	fetchOpts.Progress = func(result transport.FetchResult) { printFetchResult(result, target) }
+	fetchOpts.Locate = target.locate
+
+	fmt.Printf("Fetching from %d server(s)...\n", len(servers))
+	results := transport.FetchAll(context.Background(), cfg, app, servers, target.path, fetchOpts)
+
+	for i := range results {
+		results[i].Err = ssh.RetryTrustingHosts(results[i].Err, func() error {
+			results[i] = transport.FetchAll(context.Background(), cfg, app, []string{results[i].Host}, target.path, fetchOpts)[0]
+			return results[i].Err
+		})
 	}
 
-	creds, err := vault.Get(user.ID)
-	if err != nil {
-		return fmt.Errorf("failed to get credentials for user %s: %w", user.Name, err)
+	// Only logs still written to keep their connection for following
+	for i := range results {
+		result := &results[i]
+		if result.Source != nil && !CanFollow(app, result.RemotePath) {
+			result.Source.Close()
+			result.Source = nil
+		}
 	}
 
-	fmt.Printf("Looking for current logs: %s\n\n", app.LogPath)
+	return results, nil
+}
 
-	// Filter servers if specified
-	servers := app.Servers
-	if serverFilter != "" {
-		servers = []string{serverFilter}
+// printFetchResult reports how fetching from one server went
+func printFetchResult(result transport.FetchResult, target logTarget) {
+	switch {
+	case result.Err == nil && len(result.Files) > 1:
+		fmt.Printf("  ✓ %s: %d files (%s)\n", result.Host, len(result.Files), result.Duration.Round(time.Millisecond))
+	case result.Err == nil:
+		fmt.Printf("  ✓ %s (%s)\n", result.Host, result.Duration.Round(time.Millisecond))
+	case errors.Is(result.Err, os.ErrNotExist) && target.locate != nil:
+		// The locate function says what it looked for
+		fmt.Printf("  ✗ %s: %v\n", result.Host, result.Err)
+	case errors.Is(result.Err, os.ErrNotExist):
+		fmt.Printf("  ✗ %s: log file not found: %s\n", result.Host, result.RemotePath)
+	default:
+		fmt.Printf("  ✗ %s: %v\n", result.Host, result.Err)
 	}
+}
 
-	var downloadedFiles []string
-
-	// Connect to each server and download logs
-	for _, server := range servers {
-		fmt.Printf("Connecting to %s...\n", server)
+// compareRemoteLogs fetches the logs of several dates from each server and
+// shows them side by side in the internal viewer
+func compareRemoteLogs(cfg *config.Config, app *config.App, servers []string, targets []logTarget, dates []time.Time, opts ViewOptions) error {
+	opts.OpenWith = OpenInternal
+
+	fetched := make([][]transport.FetchResult, len(targets))
+	defer func() {
+		for _, results := range fetched {
+			for _, result := range results {
+				if result.Source != nil {
+					result.Source.Close()
+				}
+			}
+		}
+	}()
 
-		client, err := ssh.Connect(server, creds.Username, creds.Password)
+	for i, target := range targets {
+		fmt.Printf("%s:\n", dates[i].Format("2006-01-02"))
+		results, err := fetchLogs(cfg, app, servers, target, opts)
+		fetched[i] = results
 		if err != nil {
-			fmt.Printf("  ✗ Failed to connect: %v\n", err)
-			continue
+			return err
 		}
+	}
 
-		// Check if file exists
-		exists, err := client.FileExists(app.LogPath)
-		if err != nil {
-			fmt.Printf("  ✗ Error checking file: %v\n", err)
-			client.Close()
-			continue
+	// Keep each server's versions next to each other
+	var results []transport.FetchResult
+	var versions []time.Time
+	loaded := 0
+	for s := range servers {
+		for i := range targets {
+			result := fetched[i][s]
+			if result.Err == nil {
+				loaded++
+			}
+			results = append(results, result)
+			versions = append(versions, dates[i])
 		}
+	}
+	fmt.Printf("\nFetched %d of %d log(s)\n", loaded, len(results))
+	if loaded == 0 {
+		return fmt.Errorf("no log files found for the specified dates")
+	}
+
+	buffers, err := logBuffers(app, results, TimeWindow{})
+	if err != nil {
+		return err
+	}
+	for i := range buffers {
+		buffers[i].Server = fmt.Sprintf("%s @ %s", buffers[i].Server, versions[i].Format("2006-01-02"))
+		buffers[i].Date = versions[i]
+	}
+	return OpenSplitViewer(buffers)
+}
 
-		if !exists {
-			fmt.Printf("  ✗ Log file not found: %s\n", app.LogPath)
-			client.Close()
+// logBuffers reads fetched logs into viewer buffers, trimmed to a window
+// if one is set. Logs fetched over a connection that was kept open follow
+// their remote file from where the download ended.
+func logBuffers(app *config.App, results []transport.FetchResult, w TimeWindow) ([]LogBuffer, error) {
+	var buffers []LogBuffer
+	for _, result := range results {
+		buffer := LogBuffer{
+			Server:   result.Host,
+			LogFile:  LogFileLabel(result),
+			Location: app.Location(result.Host),
+		}
+		if result.Err != nil {
+			buffer.Err = result.Err
+			buffers = append(buffers, buffer)
 			continue
 		}
 
-		// Download file
-		fmt.Printf("  ↓ Downloading log file...\n")
-		localPath, err := client.DownloadFile(app.LogPath)
+		data, offset, err := ReadFetched(result, w, buffer.Location)
 		if err != nil {
-			fmt.Printf("  ✗ Failed to download: %v\n", err)
-			client.Close()
-			continue
+			return nil, err
 		}
 
-		fmt.Printf("  ✓ Downloaded to: %s\n", localPath)
-		downloadedFiles = append(downloadedFiles, localPath)
-
-		client.Close()
-	}
-
-	if len(downloadedFiles) == 0 {
-		return fmt.Errorf("no log files found")
-	}
+		buffer.Content = strings.Split(string(data), "\n")
 
-	// Open each file in editor
-	fmt.Println("\nOpening log files...")
-	for _, file := range downloadedFiles {
-		if cfg.Editor != "" {
-			if err := editor.OpenWithCustom(file, cfg.Editor); err != nil {
-				fmt.Printf("Failed to open %s: %v\n", file, err)
-			}
-		} else {
-			if err := editor.Open(file); err != nil {
-				fmt.Printf("Failed to open %s: %v\n", file, err)
+		// A closed window has nothing more to follow
+		if result.Source != nil && w.Until.IsZero() {
+			client, remotePath, inode := result.Source, result.RemotePath, result.Inode
+			buffer.Follow = func(ctx context.Context, events chan<- source.FollowEvent) error {
+				return client.Follow(ctx, remotePath, offset, inode, events)
 			}
 		}
+		buffers = append(buffers, buffer)
 	}
 
-	return nil
-}
\ No newline at end of file
+	return buffers, nil
+}
@@ -0,0 +1,250 @@
+package viewer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jatsandaruwan/logx/internal/config"
+	"github.com/jatsandaruwan/logx/internal/dates"
+	"github.com/jatsandaruwan/logx/internal/logtime"
+	"github.com/jatsandaruwan/logx/internal/source"
+	"github.com/jatsandaruwan/logx/internal/transport"
+)
+
+// windowLayouts are the forms --since and --until accept
+var windowLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// TimeWindow limits logs to the lines stamped within it. A zero Until
+// leaves the window open, so the live log can still be followed.
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ParseTimeWindow reads a window from --since and --until values, each a
+// date (YYYY-MM-DD or relative, see dates.Parse) or a date and time
+// (YYYY-MM-DD HH:MM[:SS]) in loc. A date alone as until includes the whole
+// of that day.
+func ParseTimeWindow(since, until string, loc *time.Location) (TimeWindow, error) {
+	var w TimeWindow
+	if since == "" {
+		return w, fmt.Errorf("a time window needs a start (--since)")
+	}
+
+	var err error
+	if w.Since, err = parseWindowTime(since, false, loc); err != nil {
+		return w, err
+	}
+	if until != "" {
+		if w.Until, err = parseWindowTime(until, true, loc); err != nil {
+			return w, err
+		}
+		if w.Until.Before(w.Since) {
+			return w, fmt.Errorf("time window ends before it starts")
+		}
+	}
+	return w, nil
+}
+
+func parseWindowTime(value string, end bool, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range windowLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			continue
+		}
+		if end && layout == "2006-01-02" {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+
+	// Relative days, such as yesterday or -2d
+	if t, err := dates.Parse(value, time.Now().In(loc)); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q. Use YYYY-MM-DD, YYYY-MM-DD HH:MM[:SS] or a relative day such as yesterday", value)
+}
+
+// IsZero reports whether the window is unset
+func (w TimeWindow) IsZero() bool {
+	return w.Since.IsZero() && w.Until.IsZero()
+}
+
+func (w TimeWindow) String() string {
+	const layout = "2006-01-02 15:04:05"
+	if w.Until.IsZero() {
+		return "since " + w.Since.Format(layout)
+	}
+	return w.Since.Format(layout) + " to " + w.Until.Format(layout)
+}
+
+// dayWindow returns the window covering the day of t, in t's location
+func dayWindow(t time.Time) TimeWindow {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return TimeWindow{Since: start, Until: start.AddDate(0, 0, 1).Add(-time.Nanosecond)}
+}
+
+// end is the last moment the window covers
+func (w TimeWindow) end() time.Time {
+	if w.Until.IsZero() {
+		return time.Now()
+	}
+	return w.Until
+}
+
+// WindowLog returns the path of an app's live log and a FetchOptions.Locate
+// function finding every file on a server that may hold lines from the
+// window, oldest first
+func WindowLog(app *config.App, w TimeWindow) (string, source.Locator) {
+	if app.Containers() {
+		return source.LogSpan(w.Since, w.Until), nil
+	}
+	if app.NumericRotation() {
+		return app.LogPath, source.LocateWindow(app.LogPath, w.Since, w.Until)
+	}
+
+	return app.LogPath, func(c source.Source, host string) ([]string, error) {
+		// Files are named after days on the server's clock
+		loc := app.Location(host)
+		since, end := w.Since.In(loc), w.end().In(loc)
+
+		// A dated file may be named after the day it holds or the day it was
+		// rotated, so take one day more and trim by timestamp afterwards
+		first := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, loc)
+		last := end.AddDate(0, 0, 1)
+
+		var paths []string
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			_, logPath := datedLogPath(app, day)
+			paths = append(paths, logPath)
+		}
+
+		// The live log holds everything since the last rotation
+		yesterday := time.Now().In(loc).AddDate(0, 0, -1)
+		if !end.Before(time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, loc)) {
+			paths = append(paths, app.LogPath)
+		}
+
+		return source.LocateExisting(paths)(c, host)
+	}
+}
+
+// ReadFetched returns the text of a fetched log, joining the files it was
+// fetched from and keeping only the lines within w. The size of the newest
+// file is returned too, as the offset to follow the remote log from.
+// Timestamps are read in loc.
+func ReadFetched(result transport.FetchResult, w TimeWindow, loc *time.Location) ([]byte, int64, error) {
+	files := result.Files
+	if len(files) == 0 {
+		files = []transport.FetchedFile{{RemotePath: result.RemotePath, LocalPath: result.LocalPath}}
+	}
+
+	var data []byte
+	var last int64
+	for _, file := range files {
+		part, err := os.ReadFile(file.LocalPath)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read file: %w", err)
+		}
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		data = append(data, part...)
+		last = int64(len(part))
+	}
+
+	if !w.IsZero() {
+		data = w.trim(data, loc)
+	}
+	return data, last, nil
+}
+
+// trim keeps the lines stamped within the window. Lines without a
+// timestamp go with the line before them; any before the first timestamp
+// go with the first. A log without timestamps is kept whole.
+func (w TimeWindow) trim(data []byte, loc *time.Location) []byte {
+	parser := logtime.NewParser(loc)
+
+	var out, pending bytes.Buffer
+	stamped, inside := false, false
+	for line := range bytes.Lines(data) {
+		if t, ok := parser.Parse(string(line)); ok {
+			inside = !t.Before(w.Since) && (w.Until.IsZero() || !t.After(w.Until))
+			if !stamped && inside {
+				out.Write(pending.Bytes())
+			}
+			stamped = true
+		}
+
+		switch {
+		case !stamped:
+			pending.Write(line)
+		case inside:
+			out.Write(line)
+		}
+	}
+
+	if !stamped {
+		return data
+	}
+	return out.Bytes()
+}
+
+// LogFileLabel names the file or files a log was fetched from, for the
+// viewer title
+func LogFileLabel(result transport.FetchResult) string {
+	if n := len(result.Files); n > 1 {
+		return fmt.Sprintf("%s … %s", path.Base(result.Files[0].RemotePath), path.Base(result.Files[n-1].RemotePath))
+	}
+	return path.Base(result.RemotePath)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// viewFile writes a copy of a fetched log, joined from its files and trimmed
+// to a window, for opening in an editor. The editor never gets the cached
+// download itself, so that saving in it cannot corrupt the cache.
+func viewFile(result transport.FetchResult, w TimeWindow, loc *time.Location) (string, error) {
+	data, _, err := ReadFetched(result, w, loc)
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir, err := config.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "views")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	name := result.Host + "_" + path.Base(result.RemotePath)
+	if !w.IsZero() {
+		name += "_" + w.Since.Format("20060102-150405")
+		if !w.Until.IsZero() {
+			name += "_" + w.Until.Format("20060102-150405")
+		}
+	}
+	localPath := filepath.Join(dir, unsafeFileChars.ReplaceAllString(name, "_")+".log")
+	if err := os.WriteFile(localPath, data, 0600); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
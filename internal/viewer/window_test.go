@@ -0,0 +1,156 @@
+package viewer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeWindowTrim(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 9, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		window TimeWindow
+		log    string
+		want   string
+	}{
+		{
+			name:   "closed window",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log: "2024-03-09 09:59:59 before\n" +
+				"2024-03-09 10:00:00 first\n" +
+				"2024-03-09 11:00:00 last\n" +
+				"2024-03-09 11:00:01 after\n",
+			want: "2024-03-09 10:00:00 first\n" +
+				"2024-03-09 11:00:00 last\n",
+		},
+		{
+			name:   "open window",
+			window: TimeWindow{Since: at(10, 0)},
+			log: "2024-03-09 09:00:00 before\n" +
+				"2024-03-09 12:00:00 inside\n" +
+				"2024-03-10 01:00:00 next day",
+			want: "2024-03-09 12:00:00 inside\n" +
+				"2024-03-10 01:00:00 next day",
+		},
+		{
+			name:   "continuation lines follow their line",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log: "2024-03-09 09:00:00 ERROR outside\n" +
+				"\tat Outside.java:1\n" +
+				"2024-03-09 10:30:00 ERROR inside\n" +
+				"\tat Inside.java:2\n" +
+				"2024-03-09 11:30:00 ERROR outside again\n" +
+				"\tat Outside.java:3\n",
+			want: "2024-03-09 10:30:00 ERROR inside\n" +
+				"\tat Inside.java:2\n",
+		},
+		{
+			name:   "lines before the first timestamp go with it",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log: "header\n" +
+				"2024-03-09 10:30:00 inside\n",
+			want: "header\n" +
+				"2024-03-09 10:30:00 inside\n",
+		},
+		{
+			name:   "lines before an outside first timestamp are dropped",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log: "header\n" +
+				"2024-03-09 09:30:00 outside\n" +
+				"2024-03-09 10:30:00 inside\n",
+			want: "2024-03-09 10:30:00 inside\n",
+		},
+		{
+			name:   "no timestamps keeps everything",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log:    "no\ntimestamps\nhere\n",
+			want:   "no\ntimestamps\nhere\n",
+		},
+		{
+			name:   "nothing inside",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log:    "2024-03-09 12:00:00 late\n",
+			want:   "",
+		},
+		{
+			name:   "timestamps with a zone",
+			window: TimeWindow{Since: at(10, 0), Until: at(11, 0)},
+			log: "2024-03-09T12:30:00+02:00 inside\n" +
+				"2024-03-09T10:30:00+02:00 outside\n",
+			want: "2024-03-09T12:30:00+02:00 inside\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(tt.window.trim([]byte(tt.log), time.UTC))
+			if got != tt.want {
+				t.Errorf("trim() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeWindowTrimLocation(t *testing.T) {
+	// The log is stamped in UTC+5; the window is 10:00 to 11:00 UTC
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	w := TimeWindow{
+		Since: time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 3, 9, 11, 0, 0, 0, time.UTC),
+	}
+	log := "2024-03-09 10:30:00 outside\n" +
+		"2024-03-09 15:30:00 inside\n"
+
+	if got, want := string(w.trim([]byte(log), loc)), "2024-03-09 15:30:00 inside\n"; got != want {
+		t.Errorf("trim() = %q, want %q", got, want)
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		since, until string
+		want         TimeWindow
+		wantErr      bool
+	}{
+		{
+			since: "2024-03-09 10:00", until: "2024-03-09 11:30:15",
+			want: TimeWindow{
+				Since: time.Date(2024, 3, 9, 10, 0, 0, 0, loc),
+				Until: time.Date(2024, 3, 9, 11, 30, 15, 0, loc),
+			},
+		},
+		{
+			since: "2024-03-09", until: "2024-03-09",
+			want: TimeWindow{
+				Since: time.Date(2024, 3, 9, 0, 0, 0, 0, loc),
+				Until: time.Date(2024, 3, 10, 0, 0, 0, 0, loc).Add(-time.Nanosecond),
+			},
+		},
+		{
+			since: "2024-03-09T10:00",
+			want:  TimeWindow{Since: time.Date(2024, 3, 9, 10, 0, 0, 0, loc)},
+		},
+		{since: "", until: "2024-03-09", wantErr: true},
+		{since: "2024-03-09 12:00", until: "2024-03-09 11:00", wantErr: true},
+		{since: "noon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeWindow(tt.since, tt.until, loc)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTimeWindow(%q, %q) = %v, want an error", tt.since, tt.until, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTimeWindow(%q, %q) error = %v", tt.since, tt.until, err)
+			continue
+		}
+		if !got.Since.Equal(tt.want.Since) || !got.Until.Equal(tt.want.Until) {
+			t.Errorf("ParseTimeWindow(%q, %q) = %v, want %v", tt.since, tt.until, got, tt.want)
+		}
+	}
+}